@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/sunakan/gitc/internal/git"
+)
+
+// newApplyCmd creates the apply subcommand
+func newApplyCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "apply <plan.json>",
+		Short: "Execute a cleanup plan created by \"gitc plan\"",
+		Long: `apply executes a plan created by "gitc plan" exactly as written.
+It refuses to make any changes if the tip of a branch in the plan
+has moved or the branch was deleted since the plan was made.`,
+		Args: cobra.ExactArgs(1),
+		RunE: runApply,
+	}
+}
+
+func runApply(cmd *cobra.Command, args []string) error {
+	data, err := os.ReadFile(args[0])
+	if err != nil {
+		return fmt.Errorf("failed to read plan: %w", err)
+	}
+
+	var plan git.CleanupPlan
+	if err := json.Unmarshal(data, &plan); err != nil {
+		return fmt.Errorf("failed to decode plan %s: %w", args[0], err)
+	}
+
+	repo, err := openRepository(cmd)
+	if err != nil {
+		return err
+	}
+
+	options, err := cleanupOptions(cmd, repo)
+	if err != nil {
+		return err
+	}
+
+	confirmed, err := confirmPlan(cmd, &plan, options)
+	if err != nil {
+		return fmt.Errorf("apply aborted: %w", err)
+	}
+	if !confirmed {
+		cmd.Println("Aborted. No changes were made.")
+		return nil
+	}
+
+	result, err := repo.ApplyCleanupPlan(cmd.Context(), &plan, options)
+	if err != nil {
+		printInterrupted(cmd, result, err)
+		return fmt.Errorf("apply failed: %w", err)
+	}
+
+	cmd.Printf("Default branch: %s\n", result.DefaultBranch)
+	printDecisions(cmd, result)
+	printFork(cmd, result)
+	printReturn(cmd, result)
+	printDetached(cmd, result)
+	printStash(cmd, result)
+	printErrors(cmd, result)
+
+	if result.WasDryRun {
+		cmd.Printf("\n✨ Plan verified. %d branches would be deleted.\n", len(result.DeletedBranches))
+	} else {
+		cmd.Printf("\n✨ Plan applied! Deleted %d branches.\n", len(result.DeletedBranches))
+	}
+
+	return nil
+}
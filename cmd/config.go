@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"fmt"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/sunakan/gitc/internal/config"
+	"github.com/sunakan/gitc/internal/git"
+)
+
+// newConfigCmd creates the config subcommand
+func newConfigCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Inspect gitc configuration",
+	}
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "show",
+		Short: "Show effective configuration values and where each came from",
+		Long: `show prints the effective value of every setting together with its source.
+Settings are merged in the following order (later wins):
+default, user config ($XDG_CONFIG_HOME/gitc/config.toml), repo config (.gitc.toml),
+git config (gitc.*), environment variables (GITC_*) and command-line flags.`,
+		Args: cobra.NoArgs,
+		RunE: runConfigShow,
+	})
+
+	return cmd
+}
+
+func runConfigShow(cmd *cobra.Command, args []string) error {
+	// Gitリポジトリ外ではユーザー設定と環境変数のみを表示する
+	repo, err := openRepository(cmd)
+	if git.IsNotGitRepository(err) {
+		cwd, cwdErr := git.GetCurrentDirectory()
+		if cwdErr != nil {
+			return cwdErr
+		}
+		repo, err = git.NewRepository(cwd), nil
+	}
+	if err != nil {
+		return err
+	}
+
+	cfg, err := loadConfig(cmd, repo)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "KEY\tVALUE\tSOURCE")
+	for _, setting := range cfg.Settings() {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", setting.Key, setting.Value, setting.Source)
+	}
+	return w.Flush()
+}
+
+// loadConfig は設定を読み込み、明示的に指定されたフラグの値で上書きします
+func loadConfig(cmd *cobra.Command, repo *git.Repository) (*config.Config, error) {
+	cfg, err := config.Load(cmd.Context(), repo)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+
+	flags := cmd.Flags()
+	overrides := []struct {
+		flag  string
+		key   string
+		value interface{}
+	}{
+		{flag: "default-branch", key: "default-branch", value: flagDefaultBranch},
+		{flag: "verbose", key: "verbose", value: flagVerbose},
+		{flag: "yes", key: "yes", value: flagYes},
+		{flag: "force", key: "force", value: flagForce},
+		{flag: "gone", key: "gone", value: flagGone},
+		{flag: "pull", key: "pull", value: flagPull},
+		{flag: "no-pull", key: "pull", value: string(git.PullNone)},
+		{flag: "exclude", key: "exclude", value: flagExclude},
+		{flag: "only", key: "only", value: flagOnly},
+		{flag: "dirty", key: "dirty", value: flagDirty},
+		{flag: "return", key: "return", value: flagReturn},
+		{flag: "remove-worktrees", key: "remove-worktrees", value: flagRemoveWorktrees},
+		{flag: "base-remote", key: "base-remote", value: flagBaseRemote},
+		{flag: "push-remote", key: "push-remote", value: flagPushRemote},
+		{flag: "sync-fork", key: "sync-fork", value: flagSyncFork},
+	}
+	for _, o := range overrides {
+		if flags.Lookup(o.flag) == nil || !flags.Changed(o.flag) {
+			continue
+		}
+		// --no-pull=false はプルの方法を変更しない
+		if o.flag == "no-pull" && !flagNoPull {
+			continue
+		}
+		if err := cfg.Set(o.key, o.value, config.SourceFlag); err != nil {
+			return nil, fmt.Errorf("--%s: %w", o.flag, err)
+		}
+	}
+
+	return cfg, nil
+}
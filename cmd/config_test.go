@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+)
+
+func TestConfigShow(t *testing.T) {
+	dir := createTestGitRepo(t)
+	defer changeDir(t, dir)()
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	if err := os.WriteFile(filepath.Join(dir, ".gitc.toml"), []byte("default-branch = \"trunk\"\nreturn = true\n"), 0644); err != nil {
+		t.Fatalf("Failed to write .gitc.toml: %v", err)
+	}
+	t.Setenv("GITC_VERBOSE", "true")
+
+	cmd := newRootCmd()
+	buf := bytes.Buffer{}
+	cmd.SetOut(&buf)
+	cmd.SetErr(&buf)
+	cmd.SetArgs([]string{"config", "show", "--no-pull", "--exclude", "keep"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	output := buf.String()
+	wantLines := []string{
+		`default-branch\s+"trunk"\s+repo`,
+		`return\s+true\s+repo`,
+		`verbose\s+true\s+environment`,
+		`pull\s+"none"\s+flag`,
+		`exclude\s+\["keep"\]\s+flag`,
+		`gone\s+false\s+default`,
+	}
+	for _, want := range wantLines {
+		if !regexp.MustCompile(want).MatchString(output) {
+			t.Errorf("Expected output to match %q, got:\n%s", want, output)
+		}
+	}
+}
+
+func TestConfigShow_NoPullFalse(t *testing.T) {
+	dir := createTestGitRepo(t)
+	defer changeDir(t, dir)()
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("GITC_PULL", "rebase")
+
+	cmd := newRootCmd()
+	buf := bytes.Buffer{}
+	cmd.SetOut(&buf)
+	cmd.SetErr(&buf)
+	cmd.SetArgs([]string{"config", "show", "--no-pull=false"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	// --no-pull=false はプルの方法を変更しない
+	if want := `pull\s+"rebase"\s+environment`; !regexp.MustCompile(want).MatchString(buf.String()) {
+		t.Errorf("Expected output to match %q, got:\n%s", want, buf.String())
+	}
+}
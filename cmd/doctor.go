@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/sunakan/gitc/internal/git"
+)
+
+// newDoctorCmd creates the doctor subcommand
+func newDoctorCmd() *cobra.Command {
+	var fixHead bool
+
+	cmd := &cobra.Command{
+		Use:   "doctor",
+		Short: "Check the repository for problems that affect cleanup",
+		Long: `doctor checks that refs/remotes/<remote>/HEAD of every remote is set
+and points to the branch the remote actually uses as its default
+(queried with "git ls-remote --symref"). With --fix-head a missing
+or stale remote HEAD is updated with "git remote set-head".`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runDoctor(cmd, fixHead)
+		},
+	}
+
+	cmd.Flags().BoolVar(&fixHead, "fix-head", false, "Update missing or stale refs/remotes/<remote>/HEAD to the remote's actual HEAD")
+
+	return cmd
+}
+
+func runDoctor(cmd *cobra.Command, fixHead bool) error {
+	repo, err := openRepository(cmd)
+	if err != nil {
+		return err
+	}
+
+	remotes, err := repo.ListRemotes(cmd.Context())
+	if err != nil {
+		return err
+	}
+	if len(remotes) == 0 {
+		cmd.Println("No remotes configured.")
+		return nil
+	}
+
+	problems := 0
+	for _, remote := range remotes {
+		check := repo.CheckRemoteHEAD
+		if fixHead {
+			check = repo.FixRemoteHEAD
+		}
+		status, err := check(cmd.Context(), remote)
+		if !printRemoteHEAD(cmd, status, err) {
+			problems++
+		}
+	}
+
+	if problems > 0 {
+		if !fixHead {
+			cmd.Println("\nRun \"gitc doctor --fix-head\" to fix remote HEADs.")
+		}
+		return fmt.Errorf("found %d problem(s)", problems)
+	}
+	return nil
+}
+
+// printRemoteHEAD はリモートHEADの状態を表示し、問題がない（または修復した）場合はtrueを返します
+func printRemoteHEAD(cmd *cobra.Command, status *git.RemoteHEADStatus, err error) bool {
+	ref := fmt.Sprintf("refs/remotes/%s/HEAD", status.Remote)
+	switch {
+	case err != nil:
+		cmd.Printf("❌ %s: %v\n", status.Remote, err)
+		return false
+	case status.Fixed && status.Missing():
+		cmd.Printf("🔧 %s: set %s -> %s\n", status.Remote, ref, status.Actual)
+	case status.Fixed:
+		cmd.Printf("🔧 %s: updated %s from %s to %s\n", status.Remote, ref, status.Local, status.Actual)
+	case status.Missing():
+		cmd.Printf("⚠️  %s: %s is not set (the remote's HEAD is %s)\n", status.Remote, ref, status.Actual)
+		return false
+	case status.Stale():
+		cmd.Printf("⚠️  %s: %s points to %s, but the remote's HEAD is %s\n", status.Remote, ref, status.Local, status.Actual)
+		return false
+	default:
+		cmd.Printf("✅ %s: %s -> %s\n", status.Remote, ref, status.Local)
+	}
+	return true
+}
@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDoctor(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		setHead  bool
+		wantErr  bool
+		wantOut  string
+		wantHEAD string // 実行後の refs/remotes/origin/HEAD（空の場合は未設定）
+	}{
+		{
+			name:     "origin/HEADが正しい場合",
+			args:     []string{"doctor"},
+			setHead:  true,
+			wantOut:  "✅ origin: refs/remotes/origin/HEAD -> main",
+			wantHEAD: "refs/remotes/origin/main",
+		},
+		{
+			name:    "origin/HEADがない場合は報告のみ",
+			args:    []string{"doctor"},
+			wantErr: true,
+			wantOut: "gitc doctor --fix-head",
+		},
+		{
+			name:     "--fix-headでorigin/HEADを設定",
+			args:     []string{"doctor", "--fix-head"},
+			wantOut:  "🔧 origin: set refs/remotes/origin/HEAD -> main",
+			wantHEAD: "refs/remotes/origin/main",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := createTestGitRepo(t)
+			defer changeDir(t, dir)()
+
+			// git init と git remote add で作成したリポジトリにはorigin/HEADがない
+			remote := filepath.Join(t.TempDir(), "remote.git")
+			runGit(t, dir, "init", "--bare", "-b", "main", remote)
+			runGit(t, dir, "remote", "add", "origin", remote)
+			runGit(t, dir, "push", "-u", "origin", "main")
+			if tt.setHead {
+				runGit(t, dir, "remote", "set-head", "origin", "main")
+			}
+
+			cmd := newRootCmd()
+			buf := bytes.Buffer{}
+			cmd.SetOut(&buf)
+			cmd.SetErr(&buf)
+			cmd.SetArgs(tt.args)
+
+			err := cmd.Execute()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Execute() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if output := buf.String(); !strings.Contains(output, tt.wantOut) {
+				t.Errorf("Expected output to contain %q, got %q", tt.wantOut, output)
+			}
+
+			head := runGit(t, dir, "for-each-ref", "--format=%(symref)", "refs/remotes/origin/HEAD")
+			if head != tt.wantHEAD {
+				t.Errorf("refs/remotes/origin/HEAD = %q, want %q", head, tt.wantHEAD)
+			}
+		})
+	}
+}
@@ -0,0 +1,152 @@
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/sunakan/gitc/internal/git"
+)
+
+// selectBranches はブランチごとの処理を対話的に選択させ、計画を更新します
+// 適用する場合はtrue、中止する場合はfalseを返します
+func selectBranches(in io.Reader, out io.Writer, plan *git.CleanupPlan) (bool, error) {
+	if !isInteractive(in) {
+		return false, fmt.Errorf("--interactive requires a terminal: %w", errNotInteractive)
+	}
+
+	// 選択可能なブランチ（デフォルトブランチや除外対象以外）のインデックス
+	var selectable []int
+	for i, decision := range plan.Branches {
+		if !decision.Locked {
+			selectable = append(selectable, i)
+		}
+	}
+	if len(selectable) == 0 {
+		fmt.Fprintln(out, "No deletable branches found.")
+		return true, nil
+	}
+
+	reader := bufio.NewReader(in)
+	for {
+		printSelection(out, plan, selectable)
+		fmt.Fprint(out, "Enter \"<numbers> <k|d|D>\" to keep/delete/force-delete, \"a\" to apply, \"q\" to quit: ")
+
+		line, err := reader.ReadString('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return false, fmt.Errorf("failed to read selection: %w", err)
+		}
+		if errors.Is(err, io.EOF) && strings.TrimSpace(line) == "" {
+			// 入力が途切れた場合は安全側に倒して中止する
+			fmt.Fprintln(out)
+			return false, nil
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		switch fields[0] {
+		case "a", "apply":
+			return true, nil
+		case "q", "quit":
+			return false, nil
+		}
+
+		if err := applySelection(plan, selectable, fields); err != nil {
+			fmt.Fprintf(out, "⚠️  %v\n", err)
+		}
+	}
+}
+
+// applySelection は入力されたコマンドに従って選択状態を変更します
+// 番号のみが指定された場合は keep -> delete -> force-delete の順に切り替えます
+func applySelection(plan *git.CleanupPlan, selectable []int, fields []string) error {
+	if len(fields) > 2 {
+		return fmt.Errorf("invalid input: %q", strings.Join(fields, " "))
+	}
+
+	var indexes []int
+	for _, s := range strings.Split(fields[0], ",") {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 1 || n > len(selectable) {
+			return fmt.Errorf("invalid branch number: %q", s)
+		}
+		indexes = append(indexes, selectable[n-1])
+	}
+
+	for _, i := range indexes {
+		decision := &plan.Branches[i]
+
+		action := nextAction(decision.Action)
+		if len(fields) == 2 {
+			switch fields[1] {
+			case "k":
+				action = git.BranchActionSkip
+			case "d":
+				action = git.BranchActionDelete
+			case "D":
+				action = git.BranchActionForceDelete
+			default:
+				return fmt.Errorf("invalid action: %q (use k, d or D)", fields[1])
+			}
+		}
+
+		if action != decision.Action {
+			decision.Action = action
+			decision.Reason = fmt.Sprintf("%s by user", actionLabel(action))
+		}
+	}
+
+	return nil
+}
+
+// nextAction は番号のみ入力された場合の次の処理を返します
+func nextAction(action git.BranchAction) git.BranchAction {
+	switch action {
+	case git.BranchActionSkip:
+		return git.BranchActionDelete
+	case git.BranchActionDelete:
+		return git.BranchActionForceDelete
+	default:
+		return git.BranchActionSkip
+	}
+}
+
+// actionLabel は処理内容の表示用ラベルを返します
+func actionLabel(action git.BranchAction) string {
+	if action == git.BranchActionSkip {
+		return "keep"
+	}
+	return string(action)
+}
+
+// printSelection は選択可能なブランチと現在の選択状態を表示します
+func printSelection(out io.Writer, plan *git.CleanupPlan, selectable []int) {
+	target := plan.MergeTarget
+	if target == "" {
+		target = plan.DefaultBranch
+	}
+	fmt.Fprintf(out, "\nBranches (compared with %s):\n", target)
+	for n, i := range selectable {
+		decision := plan.Branches[i]
+		fmt.Fprintf(out, "  %2d. [%-12s] %-30s +%d/-%d  %s\n",
+			n+1, actionLabel(decision.Action), decision.Branch, decision.Ahead, decision.Behind, upstreamStatus(decision))
+	}
+}
+
+// upstreamStatus は上流ブランチの状態を表示用の文字列に変換します
+func upstreamStatus(decision git.BranchDecision) string {
+	switch {
+	case decision.Upstream == "":
+		return "no upstream"
+	case decision.UpstreamTrack == "":
+		return decision.Upstream + " (up to date)"
+	default:
+		return decision.Upstream + " " + decision.UpstreamTrack
+	}
+}
@@ -0,0 +1,129 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestRootCmd_Interactive(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		wantKept []string
+		wantGone []string
+		wantOut  string
+	}{
+		{
+			name:     "ブランチごとに選択して適用",
+			input:    "1 k\n3 D\na\n",
+			wantKept: []string{"merged-a"},
+			wantGone: []string{"merged-b", "unmerged-c"},
+			wantOut:  "+1/-0",
+		},
+		{
+			name:     "番号のみで処理を切り替え",
+			input:    "3\n3\na\n",
+			wantGone: []string{"merged-a", "merged-b", "unmerged-c"},
+		},
+		{
+			name:     "中止すると何も削除しない",
+			input:    "1,2 D\nq\n",
+			wantKept: []string{"merged-a", "merged-b", "unmerged-c"},
+			wantOut:  "Aborted",
+		},
+		{
+			name:     "入力が途切れた場合は中止",
+			input:    "",
+			wantKept: []string{"merged-a", "merged-b", "unmerged-c"},
+			wantOut:  "Aborted",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := createTestGitRepo(t)
+			defer changeDir(t, dir)()
+			runGit(t, dir, "branch", "merged-a")
+			runGit(t, dir, "branch", "merged-b")
+			runGit(t, dir, "checkout", "-b", "unmerged-c")
+			runGit(t, dir, "commit", "--allow-empty", "-m", "wip")
+			runGit(t, dir, "checkout", "main")
+
+			cmd := newRootCmd()
+			buf := bytes.Buffer{}
+			cmd.SetOut(&buf)
+			cmd.SetErr(&buf)
+			cmd.SetIn(strings.NewReader(tt.input))
+			cmd.SetArgs([]string{"-i"})
+
+			if err := cmd.Execute(); err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+
+			output := buf.String()
+			if !strings.Contains(output, tt.wantOut) {
+				t.Errorf("Expected output to contain %q, got %q", tt.wantOut, output)
+			}
+
+			branches := strings.Fields(runGit(t, dir, "branch", "--format=%(refname:short)"))
+			has := func(name string) bool {
+				for _, b := range branches {
+					if b == name {
+						return true
+					}
+				}
+				return false
+			}
+			for _, name := range tt.wantKept {
+				if !has(name) {
+					t.Errorf("%s should be kept, branches = %v", name, branches)
+				}
+			}
+			for _, name := range tt.wantGone {
+				if has(name) {
+					t.Errorf("%s should be deleted, branches = %v", name, branches)
+				}
+			}
+		})
+	}
+}
+
+func TestRootCmd_InteractiveWithYes(t *testing.T) {
+	cmd := newRootCmd()
+	buf := bytes.Buffer{}
+	cmd.SetOut(&buf)
+	cmd.SetErr(&buf)
+	cmd.SetArgs([]string{"-i", "--yes"})
+
+	err := cmd.Execute()
+	if err == nil || !strings.Contains(err.Error(), "cannot be used together") {
+		t.Errorf("Execute() error = %v, want conflict error", err)
+	}
+}
+
+func TestRootCmd_InteractiveWithConfigYes(t *testing.T) {
+	dir := createTestGitRepo(t)
+	defer changeDir(t, dir)()
+	runGit(t, dir, "branch", "merged-a")
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("GITC_YES", "true")
+
+	// 設定による yes より明示的に指定された -i が優先され、選択画面が表示される
+	cmd := newRootCmd()
+	buf := bytes.Buffer{}
+	cmd.SetOut(&buf)
+	cmd.SetErr(&buf)
+	cmd.SetIn(strings.NewReader("q\n"))
+	cmd.SetArgs([]string{"-i", "--no-pull"})
+
+	if err := cmd.Execute(); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if output := buf.String(); !strings.Contains(output, "Aborted") {
+		t.Errorf("Expected output to contain %q, got %q", "Aborted", output)
+	}
+	if branches := runGit(t, dir, "branch", "--format=%(refname:short)"); !strings.Contains(branches, "merged-a") {
+		t.Errorf("merged-a should be kept, branches = %v", branches)
+	}
+}
@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/sunakan/gitc/internal/git"
+)
+
+// newMigrateDefaultCmd creates the migrate-default subcommand
+func newMigrateDefaultCmd() *cobra.Command {
+	var options git.MigrateOptions
+
+	cmd := &cobra.Command{
+		Use:   "migrate-default",
+		Short: "Follow a default branch rename on the remote (e.g. master to main)",
+		Long: `migrate-default detects that the remote's HEAD now points to a different
+branch than the local default branch, then renames the local branch,
+sets its upstream to the new remote branch, updates refs/remotes/<remote>/HEAD,
+retargets other local branches that tracked the old default branch and
+deletes the stale remote-tracking branch.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runMigrateDefault(cmd, options)
+		},
+	}
+
+	cmd.Flags().StringVar(&options.Remote, "remote", "", "Remote whose default branch was renamed (default: the primary remote)")
+	cmd.Flags().StringVar(&options.From, "from", "", "Old default branch name (default: detected from refs/remotes/<remote>/HEAD)")
+
+	return cmd
+}
+
+func runMigrateDefault(cmd *cobra.Command, options git.MigrateOptions) error {
+	if flagDryRun {
+		cmd.Println("🔍 Dry-run mode: No actual changes will be made")
+		cmd.Println()
+	}
+
+	repo, err := openRepository(cmd)
+	if err != nil {
+		return err
+	}
+
+	options.DryRun = flagDryRun
+	options.Verbose = flagVerbose
+	result, err := repo.MigrateDefaultBranch(cmd.Context(), options)
+	if result != nil {
+		printMigration(cmd, result)
+	}
+	if err != nil {
+		return fmt.Errorf("migrate-default failed: %w", err)
+	}
+
+	switch {
+	case result.UpToDate:
+	case result.WasDryRun:
+		cmd.Println("\n✨ Dry-run completed. Run without --dry-run to migrate.")
+	default:
+		cmd.Println("\n✨ Migration completed!")
+	}
+	return nil
+}
+
+// printMigration はデフォルトブランチ名の変更に追従した内容を表示します
+func printMigration(cmd *cobra.Command, result *git.MigrateResult) {
+	if result.UpToDate {
+		cmd.Printf("✅ The default branch of %s is %s. Nothing to migrate.\n", result.Remote, result.To)
+		return
+	}
+
+	cmd.Printf("Default branch of %s: %s -> %s\n", result.Remote, result.From, result.To)
+	if result.Renamed {
+		cmd.Printf("  ✏️  renamed local branch %s to %s\n", result.From, result.To)
+	}
+	if result.UpstreamSet {
+		cmd.Printf("  🔗 set upstream of %s to %s/%s\n", result.To, result.Remote, result.To)
+	}
+	if result.HEADUpdated {
+		cmd.Printf("  🎯 updated refs/remotes/%s/HEAD -> %s\n", result.Remote, result.To)
+	}
+	for _, branch := range result.Retargeted {
+		cmd.Printf("  🔁 retargeted upstream of %s to %s/%s\n", branch, result.Remote, result.To)
+	}
+	if result.DeletedTracking {
+		cmd.Printf("  🗑️  deleted stale remote-tracking branch %s/%s\n", result.Remote, result.From)
+	}
+}
@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMigrateDefault(t *testing.T) {
+	tests := []struct {
+		name         string
+		args         []string
+		wantOut      []string
+		wantBranches string
+	}{
+		{
+			name:         "ドライランでは表示のみ",
+			args:         []string{"migrate-default", "--dry-run"},
+			wantOut:      []string{"Default branch of origin: master -> main", "renamed local branch master to main", "Dry-run completed"},
+			wantBranches: "master",
+		},
+		{
+			name:         "ローカルのブランチ名を変更",
+			args:         []string{"migrate-default"},
+			wantOut:      []string{"renamed local branch master to main", "updated refs/remotes/origin/HEAD -> main", "Migration completed"},
+			wantBranches: "main",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := createTestGitRepo(t)
+			defer changeDir(t, dir)()
+			runGit(t, dir, "branch", "-m", "main", "master")
+
+			remote := filepath.Join(t.TempDir(), "remote.git")
+			runGit(t, dir, "init", "--bare", "-b", "master", remote)
+			runGit(t, dir, "remote", "add", "origin", remote)
+			runGit(t, dir, "push", "-u", "origin", "master")
+			runGit(t, dir, "remote", "set-head", "origin", "master")
+			// リモート側でデフォルトブランチの名前を変更
+			runGit(t, remote, "branch", "-m", "master", "main")
+
+			cmd := newRootCmd()
+			buf := bytes.Buffer{}
+			cmd.SetOut(&buf)
+			cmd.SetErr(&buf)
+			cmd.SetArgs(tt.args)
+
+			if err := cmd.Execute(); err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+			output := buf.String()
+			for _, want := range tt.wantOut {
+				if !strings.Contains(output, want) {
+					t.Errorf("Expected output to contain %q, got %q", want, output)
+				}
+			}
+
+			if branches := runGit(t, dir, "branch", "--format=%(refname:short)"); branches != tt.wantBranches {
+				t.Errorf("branches = %q, want %q", branches, tt.wantBranches)
+			}
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/sunakan/gitc/internal/git"
+)
+
+// newPlanCmd creates the plan subcommand
+func newPlanCmd() *cobra.Command {
+	var output string
+
+	cmd := &cobra.Command{
+		Use:   "plan",
+		Short: "Create a cleanup plan without changing local branches",
+		Long: `plan computes which branches would be deleted or skipped and
+writes the result as JSON. The plan can be reviewed and later
+executed exactly as written with "gitc apply".`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runPlan(cmd, output)
+		},
+	}
+
+	cmd.Flags().StringVarP(&output, "output", "o", "", "Write the plan to a file instead of stdout")
+
+	return cmd
+}
+
+func runPlan(cmd *cobra.Command, output string) error {
+	repo, err := openRepository(cmd)
+	if err != nil {
+		return err
+	}
+
+	options, err := cleanupOptions(cmd, repo)
+	if err != nil {
+		return err
+	}
+
+	plan, err := repo.PlanCleanup(cmd.Context(), options)
+	if err != nil {
+		return fmt.Errorf("plan failed: %w", err)
+	}
+
+	data, err := json.MarshalIndent(plan, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode plan: %w", err)
+	}
+	data = append(data, '\n')
+
+	// 出力先が指定されていない場合は標準出力にJSONのみを書き出す
+	if output == "" {
+		_, err := cmd.OutOrStdout().Write(data)
+		return err
+	}
+
+	if err := os.WriteFile(output, data, 0644); err != nil {
+		return fmt.Errorf("failed to write plan: %w", err)
+	}
+
+	cmd.Printf("Default branch: %s\n", plan.DefaultBranch)
+	printDecisions(cmd, &git.CleanupResult{Decisions: plan.Branches, WasDryRun: true})
+	cmd.Printf("\n📝 Plan written to %s. Run \"gitc apply %s\" to execute it.\n", output, output)
+
+	return nil
+}
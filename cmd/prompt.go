@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/sunakan/gitc/internal/git"
+	"golang.org/x/term"
+)
+
+// errNotInteractive は確認プロンプトを表示できない場合のエラーです
+var errNotInteractive = errors.New("stdin is not a terminal")
+
+// isInteractive は入力元が対話的に利用できるか判定します
+// テストなどで注入された*os.File以外のReaderは対話的なものとして扱います
+// /dev/null もキャラクタデバイスのため、ファイルの種類ではなく端末かどうかで判定します
+func isInteractive(in io.Reader) bool {
+	f, ok := in.(*os.File)
+	if !ok {
+		return true
+	}
+	return term.IsTerminal(int(f.Fd()))
+}
+
+// confirmDeletion は削除予定のブランチを表示し、削除してよいかユーザーに確認します
+func confirmDeletion(in io.Reader, out io.Writer, decisions []git.BranchDecision, now time.Time) (bool, error) {
+	if !isInteractive(in) {
+		return false, fmt.Errorf("confirmation required (use --yes to skip): %w", errNotInteractive)
+	}
+
+	fmt.Fprintln(out, "The following branches will be deleted:")
+	for _, decision := range decisions {
+		if decision.Action == git.BranchActionSkip {
+			continue
+		}
+		fmt.Fprintf(out, "  - %s  %s (%s)", decision.Branch, decision.Subject, formatAge(now.Sub(decision.CommitTime)))
+		// ブランチと一緒にワークツリーも削除されることを確認前に示す
+		if decision.RemoveWorktree {
+			fmt.Fprintf(out, " (removes worktree %s)", decision.Worktree)
+		}
+		fmt.Fprintln(out)
+	}
+	fmt.Fprint(out, "\nDelete these branches? [y/N]: ")
+
+	answer, err := bufio.NewReader(in).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return false, fmt.Errorf("failed to read answer: %w", err)
+	}
+	// 回答を読む前に入力が閉じられた場合は、中止ではなくエラーとして扱う
+	if errors.Is(err, io.EOF) && strings.TrimSpace(answer) == "" {
+		return false, fmt.Errorf("confirmation required (use --yes to skip): no answer before end of input")
+	}
+
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true, nil
+	default:
+		return false, nil
+	}
+}
+
+// formatAge は経過時間を人が読みやすい形式に変換します
+func formatAge(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		return pluralize(int(d/time.Minute), "minute") + " ago"
+	case d < 24*time.Hour:
+		return pluralize(int(d/time.Hour), "hour") + " ago"
+	case d < 30*24*time.Hour:
+		return pluralize(int(d/(24*time.Hour)), "day") + " ago"
+	case d < 365*24*time.Hour:
+		return pluralize(int(d/(30*24*time.Hour)), "month") + " ago"
+	default:
+		return pluralize(int(d/(365*24*time.Hour)), "year") + " ago"
+	}
+}
+
+// pluralize は数値と単位を単数形・複数形を考慮して連結します
+func pluralize(n int, unit string) string {
+	if n == 1 {
+		return fmt.Sprintf("%d %s", n, unit)
+	}
+	return fmt.Sprintf("%d %ss", n, unit)
+}
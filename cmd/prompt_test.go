@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sunakan/gitc/internal/git"
+)
+
+func TestFormatAge(t *testing.T) {
+	tests := []struct {
+		name string
+		age  time.Duration
+		want string
+	}{
+		{name: "1分未満", age: 30 * time.Second, want: "just now"},
+		{name: "単数形", age: time.Hour, want: "1 hour ago"},
+		{name: "複数形", age: 3 * 24 * time.Hour, want: "3 days ago"},
+		{name: "月単位", age: 65 * 24 * time.Hour, want: "2 months ago"},
+		{name: "年単位", age: 800 * 24 * time.Hour, want: "2 years ago"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := formatAge(tt.age); got != tt.want {
+				t.Errorf("formatAge() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfirmDeletion(t *testing.T) {
+	now := time.Date(2025, 1, 10, 0, 0, 0, 0, time.UTC)
+	decisions := []git.BranchDecision{
+		{Branch: "feature-a", Action: git.BranchActionDelete, Subject: "Add a", CommitTime: now.Add(-2 * time.Hour)},
+		{Branch: "feature-b", Action: git.BranchActionForceDelete, Subject: "Add b", CommitTime: now.Add(-3 * 24 * time.Hour),
+			Worktree: "/work/feature-b", RemoveWorktree: true},
+		{Branch: "feature-c", Action: git.BranchActionSkip, Subject: "Add c", Worktree: "/work/feature-c"},
+	}
+
+	buf := bytes.Buffer{}
+	ok, err := confirmDeletion(strings.NewReader("n\n"), &buf, decisions, now)
+	if err != nil {
+		t.Fatalf("confirmDeletion() error = %v", err)
+	}
+	if ok {
+		t.Error("confirmDeletion() = true, want false")
+	}
+
+	output := buf.String()
+	for _, want := range []string{
+		"  - feature-a  Add a (2 hours ago)\n",
+		"  - feature-b  Add b (3 days ago) (removes worktree /work/feature-b)\n",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Expected output to contain %q, got %q", want, output)
+		}
+	}
+	if strings.Contains(output, "feature-c") {
+		t.Errorf("Skipped branch should not be listed, got %q", output)
+	}
+}
@@ -1,19 +1,37 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/sunakan/gitc/internal/config"
 	"github.com/sunakan/gitc/internal/git"
 )
 
 var (
 	// フラグ変数
-	flagDryRun        bool
-	flagYes           bool
-	flagVerbose       bool
-	flagDefaultBranch string
+	flagDryRun          bool
+	flagYes             bool
+	flagVerbose         bool
+	flagDefaultBranch   string
+	flagInteractive     bool
+	flagGone            bool
+	flagForce           bool
+	flagExclude         []string
+	flagOnly            []string
+	flagPull            string
+	flagNoPull          bool
+	flagRemoveWorktrees bool
+	flagDirty           string
+	flagReturn          bool
+	flagBaseRemote      string
+	flagPushRemote      string
+	flagSyncFork        bool
 )
 
 // newRootCmd creates a new root command
@@ -27,11 +45,32 @@ and removes unnecessary local branches.`,
 		RunE: runCleanup,
 	}
 
-	// フラグの定義
-	cmd.Flags().BoolVar(&flagDryRun, "dry-run", false, "Perform a dry run without making actual changes")
-	cmd.Flags().BoolVarP(&flagYes, "yes", "y", false, "Skip confirmation prompts")
-	cmd.Flags().BoolVarP(&flagVerbose, "verbose", "v", false, "Show detailed logs")
-	cmd.Flags().StringVar(&flagDefaultBranch, "default-branch", "", "Specify the default branch to switch to")
+	// フラグの定義（サブコマンドでも共通で利用する）
+	cmd.PersistentFlags().BoolVar(&flagDryRun, "dry-run", false, "Perform a dry run without making actual changes")
+	cmd.PersistentFlags().BoolVarP(&flagYes, "yes", "y", false, "Skip confirmation prompts")
+	cmd.PersistentFlags().BoolVarP(&flagVerbose, "verbose", "v", false, "Show detailed logs")
+	cmd.PersistentFlags().StringVar(&flagDefaultBranch, "default-branch", "", "Specify the default branch to switch to")
+	cmd.PersistentFlags().BoolVar(&flagGone, "gone", false, "Only target branches whose upstream branch was deleted on the remote")
+	cmd.PersistentFlags().BoolVar(&flagForce, "force", false, "Also delete branches that are not merged into the default branch")
+	cmd.PersistentFlags().StringArrayVar(&flagExclude, "exclude", nil, `Never delete branches matching a glob (release/*) or "re:" regex (can be repeated)`)
+	cmd.PersistentFlags().StringArrayVar(&flagOnly, "only", nil, `Only delete branches matching a glob or "re:" regex (can be repeated)`)
+	cmd.PersistentFlags().StringVar(&flagPull, "pull", "ff-only", "How to pull the default branch before deleting branches: ff-only, rebase, merge or none (when pulling, branches merged into the fetched remote default branch also count as merged)")
+	cmd.PersistentFlags().BoolVar(&flagNoPull, "no-pull", false, "Skip pulling the default branch")
+	cmd.MarkFlagsMutuallyExclusive("pull", "no-pull")
+	cmd.PersistentFlags().StringVar(&flagDirty, "dirty", "abort", "What to do with uncommitted changes before switching branches: abort, stash or keep")
+	cmd.PersistentFlags().BoolVar(&flagReturn, "return", false, "Switch back to the original branch (or commit) after cleanup if it was not deleted")
+	cmd.PersistentFlags().StringVar(&flagBaseRemote, "base-remote", "", `Remote whose default branch decides merged state (default: "upstream" if it exists)`)
+	cmd.PersistentFlags().StringVar(&flagPushRemote, "push-remote", "", `Remote your branches are pushed to (default: "origin")`)
+	cmd.PersistentFlags().BoolVar(&flagSyncFork, "sync-fork", false, "Fast-forward the default branch from the base remote and push it to the push remote")
+	cmd.PersistentFlags().BoolVar(&flagRemoveWorktrees, "remove-worktrees", false, "Remove clean linked worktrees whose branch is merged, then delete the branch")
+	cmd.Flags().BoolVarP(&flagInteractive, "interactive", "i", false, "Choose keep/delete/force-delete for each branch before executing")
+
+	// サブコマンドの登録
+	cmd.AddCommand(newPlanCmd())
+	cmd.AddCommand(newApplyCmd())
+	cmd.AddCommand(newConfigCmd())
+	cmd.AddCommand(newDoctorCmd())
+	cmd.AddCommand(newMigrateDefaultCmd())
 
 	return cmd
 }
@@ -45,33 +84,55 @@ func runCleanup(cmd *cobra.Command, args []string) error {
 		cmd.Println()
 	}
 
-	// クリーンアップオプションの設定
-	options := git.CleanupOptions{
-		DryRun:        flagDryRun,
-		Verbose:       flagVerbose,
-		Yes:           flagYes,
-		DefaultBranch: flagDefaultBranch,
-		NoPull:        true, // 最小実装ではプルをスキップ
+	repo, err := openRepository(cmd)
+	if err != nil {
+		return err
+	}
+
+	options, err := cleanupOptions(cmd, repo)
+	if err != nil {
+		return err
+	}
+
+	// 実行計画の作成
+	plan, err := repo.PlanCleanup(cmd.Context(), options)
+	if err != nil {
+		return fmt.Errorf("cleanup failed: %w", err)
+	}
+
+	// 削除前の確認（対話モードではブランチの選択を確認とみなす）
+	var confirmed bool
+	if options.Interactive {
+		confirmed, err = selectBranches(cmd.InOrStdin(), cmd.OutOrStdout(), plan)
+	} else {
+		confirmed, err = confirmPlan(cmd, plan, options)
+	}
+	if err != nil {
+		return fmt.Errorf("cleanup aborted: %w", err)
+	}
+	if !confirmed {
+		cmd.Println("Aborted. No changes were made.")
+		return nil
 	}
 
 	// クリーンアップ実行
-	result, err := git.ExecuteCleanup(options)
+	result, err := repo.ApplyCleanupPlan(cmd.Context(), plan, options)
 	if err != nil {
+		printInterrupted(cmd, result, err)
 		return fmt.Errorf("cleanup failed: %w", err)
 	}
 
-	// 結果の表示（最小限）
+	// 結果の表示
 	cmd.Printf("Default branch: %s\n", result.DefaultBranch)
-	
-	if len(result.DeletedBranches) > 0 {
-		cmd.Println("\nDeleted branches:")
-		for _, branch := range result.DeletedBranches {
-			cmd.Printf("  - %s\n", branch)
-		}
-	}
+	printDecisions(cmd, result)
+	printFork(cmd, result)
+	printReturn(cmd, result)
+	printDetached(cmd, result)
+	printStash(cmd, result)
+	printErrors(cmd, result)
 
 	if flagDryRun {
-		cmd.Println("\n✨ Dry-run completed. Run without --dry-run to perform actual cleanup.")
+		cmd.Printf("\n✨ Dry-run completed. %d branches would be deleted. Run without --dry-run to perform actual cleanup.\n", len(result.DeletedBranches))
 	} else {
 		cmd.Printf("\n✨ Cleanup completed! Deleted %d branches.\n", len(result.DeletedBranches))
 	}
@@ -79,9 +140,198 @@ func runCleanup(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// confirmPlan は削除対象がある場合にユーザーの確認を求めます
+// --yes指定時、ドライラン時、削除対象がない場合は確認を省略します
+func confirmPlan(cmd *cobra.Command, plan *git.CleanupPlan, options git.CleanupOptions) (bool, error) {
+	if options.Yes || options.DryRun || !hasDeletions(plan.Branches) {
+		return true, nil
+	}
+	return confirmDeletion(cmd.InOrStdin(), cmd.OutOrStdout(), plan.Branches, time.Now())
+}
+
+// hasDeletions は削除対象のブランチが含まれているか確認します
+func hasDeletions(decisions []git.BranchDecision) bool {
+	for _, decision := range decisions {
+		if decision.Action != git.BranchActionSkip {
+			return true
+		}
+	}
+	return false
+}
+
+// openRepository はカレントディレクトリを含むリポジトリを返します
+// サブディレクトリやリンクされたワークツリーから実行した場合もリポジトリ全体が対象になります
+func openRepository(cmd *cobra.Command) (*git.Repository, error) {
+	cwd, err := git.GetCurrentDirectory()
+	if err != nil {
+		return nil, err
+	}
+	return git.OpenRepository(cmd.Context(), cwd)
+}
+
+// cleanupOptions は設定とフラグの値からクリーンアップオプションを作成します
+func cleanupOptions(cmd *cobra.Command, repo *git.Repository) (git.CleanupOptions, error) {
+	cfg, err := loadConfig(cmd, repo)
+	if err != nil {
+		return git.CleanupOptions{}, err
+	}
+
+	// 設定ファイルや環境変数の yes より、明示的に指定された --interactive を優先する
+	// （--yes と --interactive を両方指定した場合のみ Validate でエラーにする）
+	yes := cfg.Yes
+	if flagInteractive && cfg.Source("yes") != config.SourceFlag {
+		yes = false
+	}
+
+	return git.CleanupOptions{
+		DryRun:          flagDryRun,
+		Verbose:         cfg.Verbose,
+		Yes:             yes,
+		DefaultBranch:   cfg.DefaultBranch,
+		Force:           cfg.Force,
+		ExcludePatterns: cfg.Exclude,
+		OnlyPatterns:    cfg.Only,
+		ProtectPatterns: cfg.Protect,
+		Pull:            cfg.Pull,
+		Interactive:     flagInteractive,
+		Gone:            cfg.Gone,
+		RemoveWorktrees: cfg.RemoveWorktrees,
+		Dirty:           cfg.Dirty,
+		Return:          cfg.Return,
+		BaseRemote:      cfg.BaseRemote,
+		PushRemote:      cfg.PushRemote,
+		SyncFork:        cfg.SyncFork,
+	}, nil
+}
+
+// printDecisions はブランチごとの判定結果を表示します
+func printDecisions(cmd *cobra.Command, result *git.CleanupResult) {
+	deleteHeader, skipHeader := "Deleted branches:", "Skipped branches:"
+	if result.WasDryRun {
+		deleteHeader, skipHeader = "Branches to be deleted:", "Branches to be skipped:"
+	}
+
+	var deletes, protected, skips []git.BranchDecision
+	for _, decision := range result.Decisions {
+		switch {
+		case decision.Protected:
+			protected = append(protected, decision)
+		case decision.Action == git.BranchActionSkip:
+			skips = append(skips, decision)
+		default:
+			deletes = append(deletes, decision)
+		}
+	}
+
+	if len(deletes) > 0 {
+		cmd.Println("\n" + deleteHeader)
+		for _, decision := range deletes {
+			cmd.Printf("  - %s (%s)\n", decision.Branch, decision.Reason)
+		}
+	}
+
+	if len(protected) > 0 {
+		cmd.Println("\nProtected branches:")
+		for _, decision := range protected {
+			cmd.Printf("  - %s (%s)\n", decision.Branch, decision.Reason)
+		}
+	}
+
+	if len(skips) > 0 {
+		cmd.Println("\n" + skipHeader)
+		for _, decision := range skips {
+			cmd.Printf("  - %s (%s)\n", decision.Branch, decision.Reason)
+		}
+	}
+}
+
+// printInterrupted は中断された場合に、それまでに完了した処理を表示します
+func printInterrupted(cmd *cobra.Command, result *git.CleanupResult, err error) {
+	if result == nil || !git.IsCanceled(err) {
+		return
+	}
+
+	cmd.Println("Interrupted. The following actions were already completed:")
+	if result.CheckedOut {
+		cmd.Printf("  - switched to %s\n", result.DefaultBranch)
+		if result.DetachedFrom != "" {
+			cmd.Printf("    (HEAD was detached at %s)\n", result.DetachedFrom)
+		}
+	}
+	if result.Pulled {
+		cmd.Printf("  - pulled %s\n", result.DefaultBranch)
+	}
+	if result.ForkSynced != "" {
+		cmd.Printf("  - fast-forwarded %s to %s and pushed it to %s\n", result.DefaultBranch, result.MergeTarget, result.ForkSynced)
+	}
+	if result.StashRef != "" && !result.StashRestored {
+		cmd.Printf("  - stashed uncommitted changes in %s\n", result.StashRef)
+	}
+	for _, path := range result.RemovedWorktrees {
+		cmd.Printf("  - removed worktree %s\n", path)
+	}
+	for _, branch := range result.DeletedBranches {
+		cmd.Printf("  - deleted %s\n", branch)
+	}
+	if !result.CheckedOut && !result.Pulled && result.ForkSynced == "" && result.StashRef == "" && len(result.RemovedWorktrees) == 0 && len(result.DeletedBranches) == 0 {
+		cmd.Println("  (none)")
+	}
+	printErrors(cmd, result)
+}
+
+// printFork はフォークのデフォルトブランチを同期した場合に表示します
+func printFork(cmd *cobra.Command, result *git.CleanupResult) {
+	if result.ForkSynced != "" {
+		cmd.Printf("\n🍴 Fast-forwarded %s to %s and pushed it to %s.\n", result.DefaultBranch, result.MergeTarget, result.ForkSynced)
+	}
+}
+
+// printReturn はクリーンアップ後に元のブランチに戻った場合に表示します
+func printReturn(cmd *cobra.Command, result *git.CleanupResult) {
+	if result.ReturnedTo != "" {
+		cmd.Printf("\n↩️  Returned to %s.\n", result.ReturnedTo)
+	}
+}
+
+// printDetached はdetached HEADから切り替えて戻らなかった場合に、元のコミットを表示します
+func printDetached(cmd *cobra.Command, result *git.CleanupResult) {
+	if result.DetachedFrom == "" || result.ReturnedTo == result.DetachedFrom {
+		return
+	}
+	cmd.Printf("\n🔗 HEAD was detached at %s. Run \"git checkout %s\" to go back to it.\n", result.DetachedFrom, result.DetachedFrom)
+}
+
+// printStash は自動スタッシュの状態を表示します
+func printStash(cmd *cobra.Command, result *git.CleanupResult) {
+	switch {
+	case result.StashRef == "":
+	case result.StashRestored:
+		cmd.Printf("\n📦 Uncommitted changes were stashed and re-applied to the original branch.\n")
+	default:
+		cmd.Printf("\n📦 Uncommitted changes are kept in %s. Run \"git stash pop %s\" to restore them.\n", result.StashRef, result.StashRef)
+	}
+}
+
+// printErrors は処理を継続した警告・エラーを表示します
+func printErrors(cmd *cobra.Command, result *git.CleanupResult) {
+	if len(result.Errors) == 0 {
+		return
+	}
+
+	cmd.Println("\nWarnings:")
+	for _, err := range result.Errors {
+		cmd.Printf("  ⚠️  %v\n", err)
+	}
+}
+
+// Execute はルートコマンドを実行します
+// Ctrl-C（SIGINT）やSIGTERMを受け取ると実行中のgitコマンドをキャンセルします
 func Execute() {
-	if err := rootCmd.Execute(); err != nil {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := rootCmd.ExecuteContext(ctx); err != nil {
 		fmt.Fprintln(os.Stderr, err)
 		os.Exit(1)
 	}
-}
\ No newline at end of file
+}
@@ -2,8 +2,14 @@ package cmd
 
 import (
 	"bytes"
+	"context"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/sunakan/gitc/internal/git"
 )
 
 func TestRootCmd(t *testing.T) {
@@ -29,6 +35,9 @@ func TestRootCmd(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
+			// サブディレクトリからも検出されるため、リポジトリ外の一時ディレクトリで実行する
+			defer changeDir(t, t.TempDir())()
+
 			cmd := newRootCmd()
 			buf := bytes.Buffer{}
 			cmd.SetOut(&buf)
@@ -47,4 +56,341 @@ func TestRootCmd(t *testing.T) {
 			}
 		})
 	}
-}
\ No newline at end of file
+}
+
+func TestRootCmd_Confirmation(t *testing.T) {
+	tests := []struct {
+		name        string
+		args        []string
+		input       string
+		wantErr     bool
+		wantOut     string
+		wantDeleted bool
+	}{
+		{
+			name:        "yと回答すると削除する",
+			input:       "y\n",
+			wantOut:     "Delete these branches? [y/N]",
+			wantDeleted: true,
+		},
+		{
+			name:        "何も入力しないと削除しない",
+			input:       "\n",
+			wantOut:     "Aborted",
+			wantDeleted: false,
+		},
+		{
+			name:        "回答する前に入力が終わった場合はエラー",
+			input:       "",
+			wantErr:     true,
+			wantOut:     "no answer before end of input",
+			wantDeleted: false,
+		},
+		{
+			name:        "--yesで確認を省略する",
+			args:        []string{"--yes"},
+			wantOut:     "Cleanup completed",
+			wantDeleted: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := createTestGitRepo(t)
+			defer changeDir(t, dir)()
+			runGit(t, dir, "branch", "feature")
+
+			cmd := newRootCmd()
+			buf := bytes.Buffer{}
+			cmd.SetOut(&buf)
+			cmd.SetErr(&buf)
+			cmd.SetIn(strings.NewReader(tt.input))
+			cmd.SetArgs(tt.args)
+
+			err := cmd.Execute()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Execute() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			output := buf.String()
+			if !strings.Contains(output, tt.wantOut) {
+				t.Errorf("Expected output to contain %q, got %q", tt.wantOut, output)
+			}
+
+			branches := runGit(t, dir, "branch", "--format=%(refname:short)")
+			if deleted := !strings.Contains(branches, "feature"); deleted != tt.wantDeleted {
+				t.Errorf("feature deleted = %v, want %v", deleted, tt.wantDeleted)
+			}
+		})
+	}
+}
+
+func TestRootCmd_ConfirmationBareLayout(t *testing.T) {
+	// repo.git/ と feature/ ワークツリーの構成では、マージ済みブランチのワークツリーも削除される
+	src := createTestGitRepo(t)
+	runGit(t, src, "branch", "feature")
+	root := t.TempDir()
+	bare := filepath.Join(root, "repo.git")
+	runGit(t, root, "clone", "--bare", "-q", src, bare)
+	worktree := filepath.Join(root, "feature")
+	runGit(t, bare, "worktree", "add", worktree, "feature")
+	worktree = runGit(t, worktree, "rev-parse", "--show-toplevel")
+	defer changeDir(t, bare)()
+
+	tests := []struct {
+		name    string
+		args    []string
+		wantOut string
+	}{
+		{
+			name:    "確認プロンプトに削除するワークツリーを表示する",
+			args:    []string{"--no-pull"},
+			wantOut: "(removes worktree " + worktree + ")",
+		},
+		{
+			name:    "ドライランでも削除するワークツリーを表示する",
+			args:    []string{"--no-pull", "--dry-run"},
+			wantOut: "removes worktree " + worktree,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := newRootCmd()
+			buf := bytes.Buffer{}
+			cmd.SetOut(&buf)
+			cmd.SetErr(&buf)
+			cmd.SetIn(strings.NewReader("n\n"))
+			cmd.SetArgs(tt.args)
+
+			if err := cmd.Execute(); err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+			if output := buf.String(); !strings.Contains(output, tt.wantOut) {
+				t.Errorf("Expected output to contain %q, got %q", tt.wantOut, output)
+			}
+
+			if _, err := os.Stat(worktree); err != nil {
+				t.Errorf("worktree should be kept, stat error = %v", err)
+			}
+		})
+	}
+}
+
+func TestRootCmd_NonInteractiveStdin(t *testing.T) {
+	tests := []struct {
+		name string
+		open func(t *testing.T) *os.File
+	}{
+		{
+			name: "パイプ",
+			open: func(t *testing.T) *os.File {
+				r, w, err := os.Pipe()
+				if err != nil {
+					t.Fatalf("os.Pipe() error = %v", err)
+				}
+				w.Close()
+				return r
+			},
+		},
+		{
+			// /dev/null はキャラクタデバイスだが端末ではない
+			name: "/dev/null",
+			open: func(t *testing.T) *os.File {
+				f, err := os.Open(os.DevNull)
+				if err != nil {
+					t.Fatalf("os.Open() error = %v", err)
+				}
+				return f
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := createTestGitRepo(t)
+			defer changeDir(t, dir)()
+			runGit(t, dir, "branch", "feature")
+
+			// 端末ではないため、--yesなしでは失敗すること
+			in := tt.open(t)
+			defer in.Close()
+
+			cmd := newRootCmd()
+			buf := bytes.Buffer{}
+			cmd.SetOut(&buf)
+			cmd.SetErr(&buf)
+			cmd.SetIn(in)
+			cmd.SetArgs([]string{})
+
+			err := cmd.Execute()
+			if err == nil || !strings.Contains(err.Error(), "not a terminal") {
+				t.Fatalf("Execute() error = %v, want not a terminal error", err)
+			}
+
+			branches := runGit(t, dir, "branch", "--format=%(refname:short)")
+			if !strings.Contains(branches, "feature") {
+				t.Error("feature should not be deleted without confirmation")
+			}
+		})
+	}
+}
+
+func TestRootCmd_CleanupFlags(t *testing.T) {
+	tests := []struct {
+		name         string
+		args         []string
+		wantErr      string
+		wantOut      string
+		wantBranches []string
+	}{
+		{
+			name:         "--excludeを複数指定",
+			args:         []string{"--yes", "--no-pull", "--exclude", "keep-a", "--exclude", "keep-b"},
+			wantOut:      `matches exclude pattern "keep-b"`,
+			wantBranches: []string{"keep-a", "keep-b", "main"},
+		},
+		{
+			name:         "--forceで未マージブランチも削除",
+			args:         []string{"--yes", "--no-pull", "--force"},
+			wantOut:      "(--force)",
+			wantBranches: []string{"main"},
+		},
+		{
+			name:         "--pull=noneでプルしない",
+			args:         []string{"--yes", "--pull=none", "--force"},
+			wantOut:      "(--force)",
+			wantBranches: []string{"main"},
+		},
+		{
+			name:         "--pullの値を空白区切りで指定",
+			args:         []string{"--yes", "--pull", "none", "--force"},
+			wantOut:      "(--force)",
+			wantBranches: []string{"main"},
+		},
+		{
+			name:    "--pullに不正な値",
+			args:    []string{"--yes", "--pull=squash"},
+			wantErr: "must be ff-only, rebase, merge or none",
+		},
+		{
+			name:    "--pullと--no-pullの同時指定",
+			args:    []string{"--yes", "--pull=rebase", "--no-pull"},
+			wantErr: "none of the others can be",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := createTestGitRepo(t)
+			defer changeDir(t, dir)()
+			runGit(t, dir, "branch", "keep-a")
+			runGit(t, dir, "checkout", "-b", "keep-b")
+			runGit(t, dir, "commit", "--allow-empty", "-m", "wip")
+			runGit(t, dir, "checkout", "main")
+
+			cmd := newRootCmd()
+			buf := bytes.Buffer{}
+			cmd.SetOut(&buf)
+			cmd.SetErr(&buf)
+			cmd.SetArgs(tt.args)
+
+			err := cmd.Execute()
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Execute() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+
+			if output := buf.String(); !strings.Contains(output, tt.wantOut) {
+				t.Errorf("Expected output to contain %q, got %q", tt.wantOut, output)
+			}
+
+			branches := strings.Fields(runGit(t, dir, "branch", "--format=%(refname:short)"))
+			if strings.Join(branches, ",") != strings.Join(tt.wantBranches, ",") {
+				t.Errorf("branches = %v, want %v", branches, tt.wantBranches)
+			}
+		})
+	}
+}
+
+func TestPrintInterrupted(t *testing.T) {
+	tests := []struct {
+		name    string
+		result  *git.CleanupResult
+		err     error
+		wantOut []string
+		notOut  string
+	}{
+		{
+			name: "完了済みの処理を表示する",
+			result: &git.CleanupResult{
+				DefaultBranch:   "main",
+				CheckedOut:      true,
+				DeletedBranches: []string{"feature-a"},
+			},
+			err:     git.NewGitError("cleanup", context.Canceled),
+			wantOut: []string{"Interrupted.", "switched to main", "deleted feature-a"},
+		},
+		{
+			name: "detached HEADから切り替えた場合は元のコミットを表示する",
+			result: &git.CleanupResult{
+				DefaultBranch: "main",
+				CheckedOut:    true,
+				DetachedFrom:  "0123456789abcdef",
+			},
+			err:     git.NewGitError("cleanup", context.Canceled),
+			wantOut: []string{"switched to main", "HEAD was detached at 0123456789abcdef"},
+		},
+		{
+			name: "フォークの同期のみ完了した場合",
+			result: &git.CleanupResult{
+				DefaultBranch: "main",
+				MergeTarget:   "upstream/main",
+				ForkSynced:    "origin",
+			},
+			err:     git.NewGitError("cleanup", context.Canceled),
+			wantOut: []string{"fast-forwarded main to upstream/main and pushed it to origin"},
+			notOut:  "(none)",
+		},
+		{
+			name:    "何も完了していない場合",
+			result:  &git.CleanupResult{DefaultBranch: "main"},
+			err:     git.NewGitError("cleanup", context.Canceled),
+			wantOut: []string{"Interrupted.", "(none)"},
+		},
+		{
+			name:    "キャンセル以外のエラーでは表示しない",
+			result:  &git.CleanupResult{DefaultBranch: "main", CheckedOut: true},
+			err:     git.ErrStalePlan,
+			wantOut: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := &cobra.Command{}
+			buf := bytes.Buffer{}
+			cmd.SetOut(&buf)
+
+			printInterrupted(cmd, tt.result, tt.err)
+
+			output := buf.String()
+			if tt.wantOut == nil && output != "" {
+				t.Errorf("Expected no output, got %q", output)
+			}
+			for _, want := range tt.wantOut {
+				if !strings.Contains(output, want) {
+					t.Errorf("Expected output to contain %q, got %q", want, output)
+				}
+			}
+			if tt.notOut != "" && strings.Contains(output, tt.notOut) {
+				t.Errorf("Expected output not to contain %q, got %q", tt.notOut, output)
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestMain は開発者のユーザー設定（$XDG_CONFIG_HOME/gitc/config.toml、~/.gitconfig の gitc.*）や
+// GITC_* 環境変数がテスト結果に影響しないよう、設定を読み込む場所を空の一時ディレクトリに切り替えます
+func TestMain(m *testing.M) {
+	os.Exit(runIsolated(m))
+}
+
+func runIsolated(m *testing.M) int {
+	home, err := os.MkdirTemp("", "gitc-test-home")
+	if err != nil {
+		panic(err)
+	}
+	defer os.RemoveAll(home)
+
+	globalConfig := filepath.Join(home, "gitconfig")
+	if err := os.WriteFile(globalConfig, nil, 0644); err != nil {
+		panic(err)
+	}
+	os.Setenv("XDG_CONFIG_HOME", filepath.Join(home, "config"))
+	os.Setenv("GIT_CONFIG_GLOBAL", globalConfig)
+	os.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+	for _, env := range os.Environ() {
+		if key, _, _ := strings.Cut(env, "="); strings.HasPrefix(key, "GITC_") {
+			os.Unsetenv(key)
+		}
+	}
+
+	return m.Run()
+}
+
+// テスト用のGitリポジトリを作成するヘルパー関数
+func createTestGitRepo(t *testing.T) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	runGit(t, dir, "init", "-b", "main")
+	runGit(t, dir, "config", "user.email", "test@example.com")
+	runGit(t, dir, "config", "user.name", "Test User")
+	runGit(t, dir, "commit", "--allow-empty", "-m", "Initial commit")
+
+	return dir
+}
+
+// 指定ディレクトリでGitコマンドを実行するヘルパー関数
+func runGit(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %v failed: %v\n%s", args, err, out)
+	}
+
+	return strings.TrimSpace(string(out))
+}
+
+// 現在のディレクトリを変更して、テスト後に元に戻すヘルパー関数
+func changeDir(t *testing.T, dir string) func() {
+	t.Helper()
+
+	oldDir, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get current directory: %v", err)
+	}
+
+	if err := os.Chdir(dir); err != nil {
+		t.Fatalf("Failed to change directory: %v", err)
+	}
+
+	return func() {
+		if err := os.Chdir(oldDir); err != nil {
+			t.Errorf("Failed to restore directory: %v", err)
+		}
+	}
+}
@@ -0,0 +1,391 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/sunakan/gitc/internal/git"
+)
+
+// RepoFileName はリポジトリのルートにコミットするチーム共有の設定ファイル名です
+const RepoFileName = ".gitc.toml"
+
+// Source は設定値がどこから読み込まれたかを表します
+type Source string
+
+const (
+	SourceDefault   Source = "default"     // 組み込みのデフォルト値
+	SourceUser      Source = "user"        // $XDG_CONFIG_HOME/gitc/config.toml
+	SourceRepo      Source = "repo"        // リポジトリの .gitc.toml
+	SourceGitConfig Source = "git config"  // git config gitc.*
+	SourceEnv       Source = "environment" // 環境変数 GITC_*
+	SourceFlag      Source = "flag"        // コマンドラインフラグ
+)
+
+// Config はすべての設定レイヤーをマージした有効な設定値を表します
+// 各フィールドのタグはTOMLのキー、git configのキー、環境変数名を表します
+// 後から読み込まれたレイヤーほど優先され、フラグが最も優先されます
+// merge:"append" を指定した項目は上書きせず、すべてのレイヤーの値を連結します
+// repo:"deny" を指定した項目は、リポジトリにコミットできる .gitc.toml では設定できません
+// （リポジトリにコミットできる人が、すべてのクローンで未マージのブランチを確認なしに削除させられないようにするため）
+type Config struct {
+	DefaultBranch string   `key:"default-branch" git:"gitc.defaultBranch" env:"GITC_DEFAULT_BRANCH"`
+	Verbose       bool     `key:"verbose" git:"gitc.verbose" env:"GITC_VERBOSE"`
+	Yes           bool     `key:"yes" git:"gitc.yes" env:"GITC_YES" repo:"deny"`
+	Force         bool     `key:"force" git:"gitc.force" env:"GITC_FORCE" repo:"deny"`
+	Gone          bool     `key:"gone" git:"gitc.gone" env:"GITC_GONE"`
+	Pull          string   `key:"pull" git:"gitc.pull" env:"GITC_PULL"`
+	Exclude       []string `key:"exclude" git:"gitc.exclude" env:"GITC_EXCLUDE"`
+	Only          []string `key:"only" git:"gitc.only" env:"GITC_ONLY"`
+	Protect       []string `key:"protect" git:"gitc.protect" env:"GITC_PROTECT" merge:"append"`
+
+	RemoveWorktrees bool   `key:"remove-worktrees" git:"gitc.removeWorktrees" env:"GITC_REMOVE_WORKTREES" repo:"deny"`
+	Dirty           string `key:"dirty" git:"gitc.dirty" env:"GITC_DIRTY"`
+	Return          bool   `key:"return" git:"gitc.return" env:"GITC_RETURN"`
+	BaseRemote      string `key:"base-remote" git:"gitc.baseRemote" env:"GITC_BASE_REMOTE"`
+	PushRemote      string `key:"push-remote" git:"gitc.pushRemote" env:"GITC_PUSH_REMOTE"`
+	SyncFork        bool   `key:"sync-fork" git:"gitc.syncFork" env:"GITC_SYNC_FORK" repo:"deny"`
+
+	sources map[string]Source // キーごとの設定元
+}
+
+// Setting は設定値とその設定元を表します
+type Setting struct {
+	Key    string
+	Value  string
+	Source Source
+}
+
+// Default はデフォルト値の設定を返します
+func Default() *Config {
+	cfg := &Config{
+		Pull:    string(git.PullFFOnly),
+		Dirty:   "abort",
+		sources: make(map[string]Source),
+	}
+	for _, f := range fields() {
+		cfg.sources[f.key] = SourceDefault
+	}
+	return cfg
+}
+
+// Load はユーザー設定、リポジトリ設定、git config、環境変数の順に設定を読み込みます
+// repoがGitリポジトリ外を指している場合はリポジトリ設定を読み込みません
+func Load(ctx context.Context, repo *git.Repository) (*Config, error) {
+	cfg := Default()
+
+	repoRoot, err := repo.GetTopLevel(ctx)
+	if err != nil {
+		repoRoot = ""
+	}
+
+	if path := UserConfigPath(); path != "" {
+		if err := cfg.loadFile(path, SourceUser); err != nil {
+			return nil, err
+		}
+	}
+
+	if repoRoot != "" {
+		if err := cfg.loadFile(filepath.Join(repoRoot, RepoFileName), SourceRepo); err != nil {
+			return nil, err
+		}
+	}
+
+	values, err := readGitConfig(ctx, repo)
+	if err != nil {
+		return nil, err
+	}
+	if err := cfg.loadGitConfig(values); err != nil {
+		return nil, err
+	}
+
+	if err := cfg.loadEnv(os.LookupEnv); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// UserConfigPath はユーザー設定ファイルのパスを返します
+func UserConfigPath() string {
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return filepath.Join(dir, "gitc", "config.toml")
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".config", "gitc", "config.toml")
+}
+
+// Set は指定されたキーの値を設定します
+// valueにはstring、bool、[]string、またはTOMLから読み込んだ[]interface{}を指定できます
+func (c *Config) Set(key string, value interface{}, source Source) error {
+	f, ok := lookupField(key)
+	if !ok {
+		return fmt.Errorf("unknown config key %q", key)
+	}
+	if f.repoDenied && source == SourceRepo {
+		return fmt.Errorf("%s cannot be set in %s; set it in the user config, git config, environment or flags instead", key, RepoFileName)
+	}
+
+	field := reflect.ValueOf(c).Elem().Field(f.index)
+	switch field.Kind() {
+	case reflect.String:
+		// pull は以前は真偽値だったため、TOMLの true / false も受け付ける
+		if b, ok := value.(bool); ok && key == "pull" {
+			value = strconv.FormatBool(b)
+		}
+		s, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("%s: expected string, got %T", key, value)
+		}
+		field.SetString(s)
+	case reflect.Bool:
+		b, err := toBool(value)
+		if err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+		field.SetBool(b)
+	case reflect.Slice:
+		list, err := toStringList(value)
+		if err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+		if f.appendMerge {
+			list = appendUnique(field.Interface().([]string), list...)
+			if prev := c.sources[key]; prev != SourceDefault && prev != source {
+				source = prev + ", " + source
+			}
+		}
+		field.Set(reflect.ValueOf(list))
+	}
+
+	c.sources[key] = source
+	return nil
+}
+
+// Source は指定されたキーの設定元を返します
+func (c *Config) Source(key string) Source {
+	return c.sources[key]
+}
+
+// Settings はすべての設定値を設定元とともに返します
+func (c *Config) Settings() []Setting {
+	v := reflect.ValueOf(c).Elem()
+
+	var settings []Setting
+	for _, f := range fields() {
+		settings = append(settings, Setting{
+			Key:    f.key,
+			Value:  formatValue(v.Field(f.index)),
+			Source: c.sources[f.key],
+		})
+	}
+	return settings
+}
+
+// loadFile はTOMLファイルを読み込みます（存在しない場合は何もしません）
+func (c *Config) loadFile(path string, source Source) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to read config %s: %w", path, err)
+	}
+
+	var values map[string]interface{}
+	if err := toml.Unmarshal(data, &values); err != nil {
+		return fmt.Errorf("failed to parse config %s: %w", path, err)
+	}
+
+	for key, value := range values {
+		if err := c.Set(key, value, source); err != nil {
+			return fmt.Errorf("config %s: %w", path, err)
+		}
+	}
+	return nil
+}
+
+// loadGitConfig はgit configから読み込んだ値を反映します
+// git configのキーは大文字小文字を区別しないため、小文字に変換して比較します
+func (c *Config) loadGitConfig(values map[string][]string) error {
+	for _, f := range fields() {
+		vals, ok := values[strings.ToLower(f.git)]
+		if !ok || len(vals) == 0 {
+			continue
+		}
+
+		var value interface{} = vals[len(vals)-1]
+		if f.kind == reflect.Slice {
+			value = vals
+		}
+		if err := c.Set(f.key, value, SourceGitConfig); err != nil {
+			return fmt.Errorf("git config %s: %w", f.git, err)
+		}
+	}
+	return nil
+}
+
+// loadEnv は環境変数から値を反映します
+// リスト型の値はカンマ区切りで指定します
+func (c *Config) loadEnv(lookup func(string) (string, bool)) error {
+	for _, f := range fields() {
+		raw, ok := lookup(f.env)
+		if !ok {
+			continue
+		}
+
+		var value interface{} = raw
+		if f.kind == reflect.Slice {
+			value = splitList(raw)
+		}
+		if err := c.Set(f.key, value, SourceEnv); err != nil {
+			return fmt.Errorf("environment %s: %w", f.env, err)
+		}
+	}
+	return nil
+}
+
+// field は設定項目のメタデータを表します
+type field struct {
+	index int
+	key   string
+	git   string
+	env   string
+	kind  reflect.Kind
+
+	appendMerge bool // レイヤー間で値を連結するかどうか
+	repoDenied  bool // リポジトリ設定（.gitc.toml）での設定を禁止するかどうか
+}
+
+// fields はConfigのタグから設定項目の一覧を返します
+func fields() []field {
+	t := reflect.TypeOf(Config{})
+
+	var fs []field
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		key := sf.Tag.Get("key")
+		if key == "" {
+			continue
+		}
+		fs = append(fs, field{
+			index: i,
+			key:   key,
+			git:   sf.Tag.Get("git"),
+			env:   sf.Tag.Get("env"),
+			kind:  sf.Type.Kind(),
+
+			appendMerge: sf.Tag.Get("merge") == "append",
+			repoDenied:  sf.Tag.Get("repo") == "deny",
+		})
+	}
+	return fs
+}
+
+// lookupField はキーに対応する設定項目を返します
+func lookupField(key string) (field, bool) {
+	for _, f := range fields() {
+		if f.key == key {
+			return f, true
+		}
+	}
+	return field{}, false
+}
+
+// toBool は真偽値として解釈できる値をboolに変換します
+// git configと同様に yes/no, on/off も受け付けます
+func toBool(value interface{}) (bool, error) {
+	switch v := value.(type) {
+	case bool:
+		return v, nil
+	case string:
+		switch strings.ToLower(strings.TrimSpace(v)) {
+		case "yes", "on":
+			return true, nil
+		case "no", "off", "":
+			return false, nil
+		}
+		b, err := strconv.ParseBool(strings.TrimSpace(v))
+		if err != nil {
+			return false, fmt.Errorf("invalid boolean %q", v)
+		}
+		return b, nil
+	default:
+		return false, fmt.Errorf("expected boolean, got %T", value)
+	}
+}
+
+// toStringList は文字列のリストとして解釈できる値を[]stringに変換します
+func toStringList(value interface{}) ([]string, error) {
+	switch v := value.(type) {
+	case string:
+		return []string{v}, nil
+	case []string:
+		return append([]string(nil), v...), nil
+	case []interface{}:
+		list := make([]string, 0, len(v))
+		for _, item := range v {
+			s, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("expected list of strings, got %T", item)
+			}
+			list = append(list, s)
+		}
+		return list, nil
+	default:
+		return nil, fmt.Errorf("expected list of strings, got %T", value)
+	}
+}
+
+// appendUnique は重複を除いてリストに値を追加します
+func appendUnique(list []string, values ...string) []string {
+	for _, v := range values {
+		found := false
+		for _, existing := range list {
+			if existing == v {
+				found = true
+				break
+			}
+		}
+		if !found {
+			list = append(list, v)
+		}
+	}
+	return list
+}
+
+// splitList はカンマ区切りの文字列をリストに分割します
+func splitList(s string) []string {
+	var list []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+// formatValue は設定値を表示用の文字列に変換します
+func formatValue(v reflect.Value) string {
+	switch v.Kind() {
+	case reflect.Slice:
+		items := make([]string, v.Len())
+		for i := range items {
+			items[i] = strconv.Quote(v.Index(i).String())
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	case reflect.String:
+		return strconv.Quote(v.String())
+	default:
+		return fmt.Sprint(v.Interface())
+	}
+}
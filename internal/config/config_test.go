@@ -0,0 +1,186 @@
+package config
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/sunakan/gitc/internal/git"
+)
+
+// テスト用のGitリポジトリを作成するヘルパー関数
+func setupRepo(t *testing.T) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	cmd := exec.Command("git", "init")
+	cmd.Dir = dir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("Failed to init git repo: %v\n%s", err, out)
+	}
+
+	return dir
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write %s: %v", path, err)
+	}
+}
+
+func gitConfig(t *testing.T, dir string, args ...string) {
+	t.Helper()
+
+	cmd := exec.Command("git", append([]string{"config"}, args...)...)
+	cmd.Dir = dir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git config %v failed: %v\n%s", args, err, out)
+	}
+}
+
+func TestLoad_Precedence(t *testing.T) {
+	repo := setupRepo(t)
+	xdg := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", xdg)
+
+	writeFile(t, filepath.Join(xdg, "gitc", "config.toml"), `
+default-branch = "develop"
+verbose = true
+exclude = ["user-*"]
+protect = ["personal"]
+`)
+	writeFile(t, filepath.Join(repo, RepoFileName), `
+default-branch = "trunk"
+return = true
+protect = ["release/*"]
+`)
+	gitConfig(t, repo, "gitc.return", "false")
+	gitConfig(t, repo, "--add", "gitc.exclude", "git-a")
+	gitConfig(t, repo, "--add", "gitc.exclude", "git-b")
+	t.Setenv("GITC_GONE", "yes")
+	t.Setenv("GITC_PROTECT", "env-a, env-b")
+
+	cfg, err := Load(t.Context(), git.NewRepository(repo))
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	tests := []struct {
+		key        string
+		got        interface{}
+		want       interface{}
+		wantSource Source
+	}{
+		{key: "default-branch", got: cfg.DefaultBranch, want: "trunk", wantSource: SourceRepo},
+		{key: "verbose", got: cfg.Verbose, want: true, wantSource: SourceUser},
+		{key: "return", got: cfg.Return, want: false, wantSource: SourceGitConfig},
+		{key: "exclude", got: cfg.Exclude, want: []string{"git-a", "git-b"}, wantSource: SourceGitConfig},
+		{key: "gone", got: cfg.Gone, want: true, wantSource: SourceEnv},
+		{key: "pull", got: cfg.Pull, want: "ff-only", wantSource: SourceDefault},
+		{key: "protect", got: cfg.Protect, want: []string{"personal", "release/*", "env-a", "env-b"}, wantSource: "user, repo, environment"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			if !reflect.DeepEqual(tt.got, tt.want) {
+				t.Errorf("%s = %v, want %v", tt.key, tt.got, tt.want)
+			}
+			if source := cfg.Source(tt.key); source != tt.wantSource {
+				t.Errorf("Source(%s) = %q, want %q", tt.key, source, tt.wantSource)
+			}
+		})
+	}
+}
+
+func TestConfig_Set(t *testing.T) {
+	tests := []struct {
+		name    string
+		key     string
+		value   interface{}
+		wantErr bool
+	}{
+		{name: "文字列", key: "default-branch", value: "main"},
+		{name: "真偽値", key: "force", value: true},
+		{name: "真偽値（文字列）", key: "force", value: "on"},
+		{name: "リスト", key: "exclude", value: []string{"a", "b"}},
+		{name: "プルの方法", key: "pull", value: "rebase"},
+		{name: "プルの方法（以前の真偽値）", key: "pull", value: false},
+		{name: "不明なキー", key: "unknown", value: "x", wantErr: true},
+		{name: "不正な真偽値", key: "force", value: "maybe", wantErr: true},
+		{name: "型の不一致", key: "default-branch", value: true, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Default()
+			err := cfg.Set(tt.key, tt.value, SourceFlag)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Set() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && cfg.Source(tt.key) != SourceFlag {
+				t.Errorf("Source(%s) = %q, want %q", tt.key, cfg.Source(tt.key), SourceFlag)
+			}
+		})
+	}
+}
+
+func TestLoad_InvalidFile(t *testing.T) {
+	repo := setupRepo(t)
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	writeFile(t, filepath.Join(repo, RepoFileName), "unknown-key = 1\n")
+
+	if _, err := Load(t.Context(), git.NewRepository(repo)); err == nil {
+		t.Error("Load() expected error for unknown key")
+	}
+}
+
+func TestLoad_RepoDeniedKeys(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{name: "force", content: "force = true\n"},
+		{name: "yes", content: "yes = true\n"},
+		{name: "remove-worktrees", content: "remove-worktrees = true\n"},
+		{name: "sync-fork", content: "sync-fork = true\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := setupRepo(t)
+			t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+			writeFile(t, filepath.Join(repo, RepoFileName), tt.content)
+
+			_, err := Load(t.Context(), git.NewRepository(repo))
+			if err == nil || !strings.Contains(err.Error(), tt.name+" cannot be set in "+RepoFileName) {
+				t.Errorf("Load() error = %v, want %s to be refused", err, tt.name)
+			}
+		})
+	}
+
+	t.Run("ユーザー設定では設定できる", func(t *testing.T) {
+		repo := setupRepo(t)
+		home := t.TempDir()
+		t.Setenv("XDG_CONFIG_HOME", home)
+
+		writeFile(t, filepath.Join(home, "gitc", "config.toml"), "force = true\nyes = true\n")
+
+		cfg, err := Load(t.Context(), git.NewRepository(repo))
+		if err != nil {
+			t.Fatalf("Load() error = %v", err)
+		}
+		if !cfg.Force || !cfg.Yes {
+			t.Errorf("Force = %v, Yes = %v, want true", cfg.Force, cfg.Yes)
+		}
+	})
+}
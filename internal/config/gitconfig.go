@@ -0,0 +1,37 @@
+package config
+
+import (
+	"context"
+	"strings"
+
+	"github.com/sunakan/gitc/internal/git"
+)
+
+// readGitConfig は git config の gitc.* キーをすべて読み込みます
+// キーは小文字に正規化され、複数値キーはすべての値を保持します
+func readGitConfig(ctx context.Context, repo *git.Repository) (map[string][]string, error) {
+	values := make(map[string][]string)
+
+	result, err := repo.ExecuteCommand(ctx, "config", "--get-regexp", `^gitc\.`)
+	if err != nil {
+		// 一致するキーがない場合は終了コード1でエラー出力なしとなる
+		if result != nil && result.Output == "" && result.Error == "" {
+			return values, nil
+		}
+		return nil, git.NewGitError("config", err)
+	}
+
+	for _, line := range strings.Split(result.Output, "\n") {
+		if line == "" {
+			continue
+		}
+		key, value, found := strings.Cut(line, " ")
+		if !found {
+			// 値のないキー（例: [gitc] force）はtrueとして扱う
+			value = "true"
+		}
+		key = strings.ToLower(key)
+		values[key] = append(values[key], value)
+	}
+	return values, nil
+}
@@ -1,69 +1,221 @@
 package git
 
 import (
+	"context"
 	"fmt"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
 )
 
+// DefaultBranchStrategy はデフォルトブランチを検出した方法を表します
+type DefaultBranchStrategy string
+
+const (
+	DefaultBranchFromOption       DefaultBranchStrategy = "option"             // オプションや設定で指定された
+	DefaultBranchFromRemoteHEAD   DefaultBranchStrategy = "remote-head"        // refs/remotes/<remote>/HEAD
+	DefaultBranchFromRemoteQuery  DefaultBranchStrategy = "ls-remote"          // git ls-remote --symref <remote> HEAD
+	DefaultBranchFromInitConfig   DefaultBranchStrategy = "init.defaultBranch" // git config init.defaultBranch
+	DefaultBranchFromLocalBranch  DefaultBranchStrategy = "local-branch"       // 一般的な名前のローカルブランチ
+	DefaultBranchFromRemoteBranch DefaultBranchStrategy = "remote-branch"      // 一般的な名前のリモートブランチ
+)
+
+// commonDefaultBranches はデフォルトブランチとしてよく使われる名前です（優先順）
+var commonDefaultBranches = []string{"main", "master", "develop", "dev", "trunk"}
+
+// DefaultBranch はデフォルトブランチの検出結果を表します
+type DefaultBranch struct {
+	Name     string                // ブランチ名（"release/main" のように "/" を含む場合がある）
+	Remote   string                // 検出に使用したリモート（リモートがない場合は空）
+	Strategy DefaultBranchStrategy // 検出した方法
+	Repaired bool                  // 検出時に refs/remotes/<remote>/HEAD を修復したかどうか
+}
+
+// Describe は検出した方法の説明を返します（--verbose用）
+func (d *DefaultBranch) Describe() string {
+	switch d.Strategy {
+	case DefaultBranchFromOption:
+		return "オプションで指定"
+	case DefaultBranchFromRemoteHEAD:
+		return fmt.Sprintf("refs/remotes/%s/HEAD から検出", d.Remote)
+	case DefaultBranchFromRemoteQuery:
+		if d.Repaired {
+			return fmt.Sprintf("%s に問い合わせて検出し、refs/remotes/%s/HEAD を修復", d.Remote, d.Remote)
+		}
+		return fmt.Sprintf("%s に問い合わせて検出", d.Remote)
+	case DefaultBranchFromInitConfig:
+		return "init.defaultBranch の設定から検出"
+	case DefaultBranchFromLocalBranch:
+		return "一般的なブランチ名のローカルブランチから推測"
+	case DefaultBranchFromRemoteBranch:
+		return fmt.Sprintf("一般的なブランチ名の %s のリモートブランチから推測", d.Remote)
+	default:
+		return string(d.Strategy)
+	}
+}
+
 // DetectDefaultBranch はリポジトリのデフォルトブランチを検出します
-func DetectDefaultBranch() (string, error) {
+func (r *Repository) DetectDefaultBranch(ctx context.Context) (string, error) {
+	branch, err := r.ResolveDefaultBranch(ctx)
+	if err != nil {
+		return "", err
+	}
+	return branch.Name, nil
+}
+
+// ResolveDefaultBranch はリポジトリのデフォルトブランチを検出し、検出した方法とともに返します
+// 主要なリモートのHEAD、init.defaultBranch、一般的なブランチ名の順に確認します
+// refs/remotes/<remote>/HEAD がない場合（git init と git remote add で作成したリポジトリなど）は、
+// リモートに問い合わせてから git remote set-head で修復します
+func (r *Repository) ResolveDefaultBranch(ctx context.Context) (*DefaultBranch, error) {
+	remote, err := r.PrimaryRemote(ctx)
+	if err != nil {
+		return nil, NewGitError("detect-default-branch", err).WithMessage("failed to list remotes")
+	}
+	return r.ResolveDefaultBranchFrom(ctx, remote)
+}
+
+// ResolveDefaultBranchFrom は指定したリモートを基準にデフォルトブランチを検出します（remoteが空の場合はローカルのみ）
+// フォーク運用でフォーク元（upstream）のデフォルトブランチを検出する場合などに使用します
+func (r *Repository) ResolveDefaultBranchFrom(ctx context.Context, remote string) (*DefaultBranch, error) {
 	// リモートHEADからデフォルトブランチを取得してみる
-	result, err := ExecuteCommand("symbolic-ref", "refs/remotes/origin/HEAD")
-	if err == nil && result.Output != "" {
-		// refs/remotes/origin/main形式からブランチ名を抽出
-		parts := strings.Split(result.Output, "/")
-		if len(parts) > 0 {
-			return parts[len(parts)-1], nil
+	if remote != "" {
+		if name, ok := r.remoteHEAD(ctx, remote); ok {
+			return &DefaultBranch{Name: name, Remote: remote, Strategy: DefaultBranchFromRemoteHEAD}, nil
+		}
+		if name, err := r.QueryRemoteHEAD(ctx, remote); err == nil {
+			// まだフェッチしていない場合は修復できないが、リモートのHEADは信頼できるためそのまま使用する
+			repaired := r.SetRemoteHEAD(ctx, remote, name) == nil
+			return &DefaultBranch{Name: name, Remote: remote, Strategy: DefaultBranchFromRemoteQuery, Repaired: repaired}, nil
+		} else if IsCanceled(err) {
+			return nil, NewGitError("detect-default-branch", err)
 		}
 	}
-	
+
+	// git initで作成されるブランチ名の設定を確認
+	if values, err := r.getConfigValues(ctx, "init.defaultBranch"); err == nil && len(values) > 0 {
+		name := values[len(values)-1]
+		if r.refExists(ctx, "refs/heads/"+name) || (remote != "" && r.refExists(ctx, "refs/remotes/"+remote+"/"+name)) {
+			return &DefaultBranch{Name: name, Remote: remote, Strategy: DefaultBranchFromInitConfig}, nil
+		}
+	}
+
 	// フォールバック: 一般的なデフォルトブランチ名を確認
-	commonDefaults := []string{"main", "master", "develop", "dev"}
-	branches, err := ListLocalBranches()
+	branches, err := r.ListLocalBranches(ctx)
 	if err != nil {
-		return "", NewGitError("detect-default-branch", err).WithMessage("failed to list branches")
+		return nil, NewGitError("detect-default-branch", err).WithMessage("failed to list branches")
 	}
-	
-	for _, defaultName := range commonDefaults {
-		for _, branch := range branches {
-			if branch == defaultName {
-				return branch, nil
-			}
+	for _, defaultName := range commonDefaultBranches {
+		if slices.Contains(branches, defaultName) {
+			return &DefaultBranch{Name: defaultName, Remote: remote, Strategy: DefaultBranchFromLocalBranch}, nil
 		}
 	}
-	
-	// まだ見つからない場合は、リモートブランチを確認
-	remoteBranches, err := ListRemoteBranches()
-	if err == nil {
-		for _, defaultName := range commonDefaults {
-			for _, branch := range remoteBranches {
-				if strings.HasSuffix(branch, "/"+defaultName) {
-					return defaultName, nil
+
+	// まだ見つからない場合は、主要なリモートのブランチを確認
+	if remote != "" {
+		remoteBranches, err := r.ListRemoteBranches(ctx)
+		if err == nil {
+			for _, defaultName := range commonDefaultBranches {
+				if slices.Contains(remoteBranches, remote+"/"+defaultName) {
+					return &DefaultBranch{Name: defaultName, Remote: remote, Strategy: DefaultBranchFromRemoteBranch}, nil
 				}
 			}
 		}
 	}
-	
-	return "", NewGitError("detect-default-branch", ErrNoDefaultBranch)
+
+	return nil, NewGitError("detect-default-branch", ErrNoDefaultBranch)
+}
+
+// remoteHEAD はrefs/remotes/<remote>/HEADが指すブランチ名を返します
+// ブランチ名に "/" が含まれる場合（例: release/main）も正しく扱うため、接頭辞を取り除いて求めます
+func (r *Repository) remoteHEAD(ctx context.Context, remote string) (string, bool) {
+	prefix := "refs/remotes/" + remote + "/"
+	result, err := r.ExecuteCommand(ctx, "symbolic-ref", "--quiet", prefix+"HEAD")
+	if err != nil || !strings.HasPrefix(result.Output, prefix) {
+		return "", false
+	}
+	name := strings.TrimPrefix(result.Output, prefix)
+	return name, name != ""
+}
+
+// refExists は指定した参照が存在するか確認します
+func (r *Repository) refExists(ctx context.Context, ref string) bool {
+	_, err := r.ExecuteCommand(ctx, "rev-parse", "--verify", "--quiet", ref)
+	return err == nil
+}
+
+// HeadState は現在のHEADの状態を表します
+type HeadState struct {
+	Branch   string // チェックアウトしているブランチ（detached HEADの場合は空）
+	Commit   string // HEADが指すコミット（まだコミットがない場合は空）
+	Detached bool   // detached HEADかどうか
+}
+
+// String はブランチ名（detached HEADの場合は "detached HEAD at <commit>"）を返します
+func (h HeadState) String() string {
+	if h.Detached {
+		return fmt.Sprintf("detached HEAD at %s", shortSHA(h.Commit))
+	}
+	return h.Branch
+}
+
+// GetHead は現在のHEADの状態を返します
+// rev-parse --abbrev-ref HEAD はdetached HEADの場合に "HEAD" を返してしまうため、
+// symbolic-refでブランチをチェックアウトしているかどうかを明示的に判定します
+func (r *Repository) GetHead(ctx context.Context) (*HeadState, error) {
+	head := &HeadState{}
+
+	// detached HEADの場合は終了コード1でエラー出力なしとなる
+	result, err := r.ExecuteCommand(ctx, "symbolic-ref", "--quiet", "--short", "HEAD")
+	switch {
+	case err == nil:
+		head.Branch = result.Output
+	case result != nil && result.Error == "" && !IsCanceled(err):
+		head.Detached = true
+	default:
+		return nil, NewGitError("get-current-branch", err)
+	}
+
+	// まだコミットがないブランチでは終了コード1でエラー出力なしとなる
+	result, err = r.ExecuteCommand(ctx, "rev-parse", "--verify", "--quiet", "HEAD")
+	switch {
+	case err == nil:
+		head.Commit = result.Output
+	case result != nil && result.Error == "" && !IsCanceled(err) && !head.Detached:
+	default:
+		return nil, NewGitError("get-current-branch", err)
+	}
+
+	return head, nil
 }
 
 // GetCurrentBranch は現在のブランチ名を返します
-func GetCurrentBranch() (string, error) {
-	result, err := ExecuteCommand("rev-parse", "--abbrev-ref", "HEAD")
+// detached HEADの場合はErrDetachedHeadを返します（コミットが必要な場合はGetHeadを使用してください）
+func (r *Repository) GetCurrentBranch(ctx context.Context) (string, error) {
+	head, err := r.GetHead(ctx)
 	if err != nil {
-		return "", NewGitError("get-current-branch", err)
+		return "", err
 	}
-	
-	if result.Output == "" {
-		return "", NewGitError("get-current-branch", fmt.Errorf("no output from git command"))
+	if head.Detached {
+		return "", NewGitError("get-current-branch", ErrDetachedHead).WithPath(shortSHA(head.Commit))
 	}
-	
-	return result.Output, nil
+	return head.Branch, nil
+}
+
+// IsReachable は指定したコミットがいずれかの参照（ブランチ・タグ・リモートブランチなど）から到達可能か確認します
+// 到達できないコミットはreflogの期限が切れるとガベージコレクションで失われます
+func (r *Repository) IsReachable(ctx context.Context, commit string) (bool, error) {
+	result, err := r.ExecuteCommand(ctx, "for-each-ref", "--count=1", "--format=%(refname)", "--contains", commit)
+	if err != nil {
+		return false, NewGitError("check-reachable", err).WithPath(shortSHA(commit))
+	}
+	return result.Output != "", nil
 }
 
 // ListLocalBranches はすべてのローカルブランチの一覧を返します
-func ListLocalBranches() ([]string, error) {
-	result, err := ExecuteCommand("branch", "--format=%(refname:short)")
+func (r *Repository) ListLocalBranches(ctx context.Context) ([]string, error) {
+	result, err := r.ExecuteCommand(ctx, "branch", "--format=%(refname:short)")
 	if err != nil {
 		return nil, NewGitError("list-local-branches", err)
 	}
@@ -76,9 +228,102 @@ func ListLocalBranches() ([]string, error) {
 	return filterEmptyStrings(branches), nil
 }
 
+// BranchInfo はローカルブランチの情報を表します
+type BranchInfo struct {
+	Name          string    // ブランチ名
+	SHA           string    // ブランチ先端のコミット
+	CommitTime    time.Time // ブランチ先端のコミット日時
+	Upstream      string    // 上流ブランチ（未設定の場合は空）
+	UpstreamTrack string    // 上流ブランチとの差分（例: "[ahead 1]", "[gone]"）
+	Subject       string    // ブランチ先端のコミットの件名
+}
+
+// IsUpstreamGone は上流ブランチが設定されているがリモートから削除済みかどうかを返します
+func (b BranchInfo) IsUpstreamGone() bool {
+	return b.UpstreamTrack == "[gone]"
+}
+
+// ListBranchInfos はすべてのローカルブランチの情報を返します
+func (r *Repository) ListBranchInfos(ctx context.Context) ([]BranchInfo, error) {
+	// 件名にはタブが含まれる可能性があるため最後に配置する
+	format := "--format=%(refname:short)%09%(objectname)%09%(committerdate:unix)%09%(upstream:short)%09%(upstream:track)%09%(subject)"
+	result, err := r.ExecuteCommand(ctx, "for-each-ref", format, "refs/heads")
+	if err != nil {
+		return nil, NewGitError("list-local-branches", err)
+	}
+
+	var infos []BranchInfo
+	for _, line := range strings.Split(result.Output, "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		fields := strings.SplitN(line, "\t", 6)
+		if len(fields) != 6 {
+			return nil, NewGitError("list-local-branches", fmt.Errorf("unexpected output: %q", line))
+		}
+		unix, err := strconv.ParseInt(fields[2], 10, 64)
+		if err != nil {
+			return nil, NewGitError("list-local-branches", fmt.Errorf("invalid commit date %q: %w", fields[2], err))
+		}
+		infos = append(infos, BranchInfo{
+			Name:          fields[0],
+			SHA:           fields[1],
+			CommitTime:    time.Unix(unix, 0).UTC(),
+			Upstream:      fields[3],
+			UpstreamTrack: fields[4],
+			Subject:       fields[5],
+		})
+	}
+	return infos, nil
+}
+
+// CountAheadBehind はbaseと比較したbranchの先行・遅行コミット数を返します
+func (r *Repository) CountAheadBehind(ctx context.Context, base, branch string) (ahead int, behind int, err error) {
+	result, err := r.ExecuteCommand(ctx, "rev-list", "--left-right", "--count", base+"..."+branch)
+	if err != nil {
+		return 0, 0, NewGitError("count-ahead-behind", err).WithPath(branch)
+	}
+
+	fields := strings.Fields(result.Output)
+	if len(fields) != 2 {
+		return 0, 0, NewGitError("count-ahead-behind", fmt.Errorf("unexpected output: %q", result.Output)).WithPath(branch)
+	}
+	if behind, err = strconv.Atoi(fields[0]); err != nil {
+		return 0, 0, NewGitError("count-ahead-behind", err).WithPath(branch)
+	}
+	if ahead, err = strconv.Atoi(fields[1]); err != nil {
+		return 0, 0, NewGitError("count-ahead-behind", err).WithPath(branch)
+	}
+	return ahead, behind, nil
+}
+
+// branchNames はブランチ情報のスライスからブランチ名の一覧を返します
+func branchNames(infos []BranchInfo) []string {
+	names := make([]string, 0, len(infos))
+	for _, info := range infos {
+		names = append(names, info.Name)
+	}
+	return names
+}
+
+// ListMergedBranches は指定されたブランチにマージ済みのローカルブランチの一覧を返します
+func (r *Repository) ListMergedBranches(ctx context.Context, target string) ([]string, error) {
+	result, err := r.ExecuteCommand(ctx, "branch", "--merged", target, "--format=%(refname:short)")
+	if err != nil {
+		return nil, NewGitError("list-merged-branches", err).WithPath(target)
+	}
+
+	if result.Output == "" {
+		return []string{}, nil
+	}
+
+	branches := strings.Split(result.Output, "\n")
+	return filterEmptyStrings(branches), nil
+}
+
 // ListRemoteBranches はすべてのリモートブランチの一覧を返します
-func ListRemoteBranches() ([]string, error) {
-	result, err := ExecuteCommand("branch", "-r", "--format=%(refname:short)")
+func (r *Repository) ListRemoteBranches(ctx context.Context) ([]string, error) {
+	result, err := r.ExecuteCommand(ctx, "branch", "-r", "--format=%(refname:short)")
 	if err != nil {
 		return nil, NewGitError("list-remote-branches", err)
 	}
@@ -92,8 +337,8 @@ func ListRemoteBranches() ([]string, error) {
 }
 
 // CheckoutBranch は指定されたブランチに切り替えます
-func CheckoutBranch(branch string) error {
-	_, err := ExecuteCommand("checkout", branch)
+func (r *Repository) CheckoutBranch(ctx context.Context, branch string) error {
+	_, err := r.ExecuteCommand(ctx, "checkout", branch)
 	if err != nil {
 		return NewGitError("checkout", err).WithPath(branch)
 	}
@@ -101,13 +346,13 @@ func CheckoutBranch(branch string) error {
 }
 
 // DeleteBranch は指定されたローカルブランチを削除します
-func DeleteBranch(branch string, force bool) error {
+func (r *Repository) DeleteBranch(ctx context.Context, branch string, force bool) error {
 	args := []string{"branch", "-d", branch}
 	if force {
 		args[1] = "-D"
 	}
 	
-	_, err := ExecuteCommand(args...)
+	_, err := r.ExecuteCommand(ctx, args...)
 	if err != nil {
 		return NewGitError("delete-branch", err).WithPath(branch)
 	}
@@ -115,9 +360,9 @@ func DeleteBranch(branch string, force bool) error {
 }
 
 // BranchExists は指定されたブランチが存在するかチェックします
-func BranchExists(branch string) (bool, error) {
+func (r *Repository) BranchExists(ctx context.Context, branch string) (bool, error) {
 	// ローカルブランチをチェック
-	localBranches, err := ListLocalBranches()
+	localBranches, err := r.ListLocalBranches(ctx)
 	if err != nil {
 		return false, NewGitError("check-branch-exists", err).WithMessage("failed to list local branches")
 	}
@@ -129,7 +374,7 @@ func BranchExists(branch string) (bool, error) {
 	}
 
 	// リモートブランチもチェック
-	remoteBranches, err := ListRemoteBranches()
+	remoteBranches, err := r.ListRemoteBranches(ctx)
 	if err != nil {
 		// リモートブランチの取得に失敗した場合は警告として扱い、ローカルのみの結果を返す
 		return false, nil
@@ -145,6 +390,19 @@ func BranchExists(branch string) (bool, error) {
 	return false, nil
 }
 
+// LocalBranchExists は指定されたローカルブランチが存在するかチェックします（リモートブランチは対象外）
+func (r *Repository) LocalBranchExists(ctx context.Context, branch string) (bool, error) {
+	result, err := r.ExecuteCommand(ctx, "rev-parse", "--verify", "--quiet", "refs/heads/"+branch)
+	if err != nil {
+		// 存在しない場合は終了コード1でエラー出力なしとなる
+		if result != nil && result.Error == "" {
+			return false, nil
+		}
+		return false, NewGitError("check-branch-exists", err).WithPath(branch)
+	}
+	return true, nil
+}
+
 // filterEmptyStrings はスライスから空文字列を除去します
 func filterEmptyStrings(strs []string) []string {
 	var filtered []string
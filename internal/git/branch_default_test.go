@@ -7,6 +7,8 @@ import (
 )
 
 func TestBranchExists(t *testing.T) {
+	t.Parallel()
+
 	if !isIntegrationTest() {
 		t.Skip("統合テスト環境でのみ実行")
 	}
@@ -14,8 +16,7 @@ func TestBranchExists(t *testing.T) {
 	// テスト用のGitリポジトリをセットアップ
 	repoPath, cleanup := createTestGitRepo(t)
 	defer cleanup()
-	restoreDir := changeDir(t, repoPath)
-	defer restoreDir()
+	repo := NewRepository(repoPath)
 
 	// テスト用ブランチを作成
 	cmd := exec.Command("git", "checkout", "-b", "test-branch")
@@ -59,7 +60,7 @@ func TestBranchExists(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			exists, err := BranchExists(tt.branch)
+			exists, err := repo.BranchExists(t.Context(), tt.branch)
 
 			if tt.wantError && err == nil {
 				t.Errorf("BranchExists() expected error but got none")
@@ -75,6 +76,8 @@ func TestBranchExists(t *testing.T) {
 }
 
 func TestDefaultBranchOption(t *testing.T) {
+	t.Parallel()
+
 	if !isIntegrationTest() {
 		t.Skip("統合テスト環境でのみ実行")
 	}
@@ -82,8 +85,7 @@ func TestDefaultBranchOption(t *testing.T) {
 	// テスト用のGitリポジトリをセットアップ
 	repoPath, cleanup := createTestGitRepo(t)
 	defer cleanup()
-	restoreDir := changeDir(t, repoPath)
-	defer restoreDir()
+	repo := NewRepository(repoPath)
 
 	// テスト用ブランチを作成
 	cmd := exec.Command("git", "checkout", "-b", "custom-default")
@@ -133,7 +135,7 @@ func TestDefaultBranchOption(t *testing.T) {
 				Yes:           true,
 			}
 
-			result, err := ExecuteCleanup(options)
+			result, err := repo.ExecuteCleanup(t.Context(), options)
 
 			if tt.wantError {
 				if err == nil {
@@ -159,6 +161,8 @@ func TestDefaultBranchOption(t *testing.T) {
 }
 
 func TestDefaultBranchValidation(t *testing.T) {
+	t.Parallel()
+
 	if !isIntegrationTest() {
 		t.Skip("統合テスト環境でのみ実行")
 	}
@@ -166,8 +170,7 @@ func TestDefaultBranchValidation(t *testing.T) {
 	// テスト用のGitリポジトリをセットアップ
 	repoPath, cleanup := createTestGitRepo(t)
 	defer cleanup()
-	restoreDir := changeDir(t, repoPath)
-	defer restoreDir()
+	repo := NewRepository(repoPath)
 
 	// 存在しないブランチでのテスト
 	options := CleanupOptions{
@@ -176,7 +179,7 @@ func TestDefaultBranchValidation(t *testing.T) {
 		Yes:           true,
 	}
 
-	_, err := ExecuteCleanup(options)
+	_, err := repo.ExecuteCleanup(t.Context(), options)
 	if err == nil {
 		t.Error("ExecuteCleanup() should fail with non-existent branch")
 	}
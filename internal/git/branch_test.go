@@ -1,8 +1,10 @@
 package git
 
 import (
+	"errors"
 	"reflect"
 	"testing"
+	"time"
 )
 
 func TestFilterEmptyStrings(t *testing.T) {
@@ -64,4 +66,310 @@ func TestFilterEmptyStrings(t *testing.T) {
 			}
 		})
 	}
-}
\ No newline at end of file
+}
+
+func TestCountAheadBehind(t *testing.T) {
+	t.Parallel()
+
+	dir, cleanup := createTestGitRepo(t)
+	defer cleanup()
+	repo := NewRepository(dir)
+
+	runGit(t, dir, "checkout", "-b", "feature")
+	runGit(t, dir, "commit", "--allow-empty", "-m", "feature 1")
+	runGit(t, dir, "commit", "--allow-empty", "-m", "feature 2")
+	runGit(t, dir, "checkout", "main")
+	runGit(t, dir, "commit", "--allow-empty", "-m", "main 1")
+
+	ahead, behind, err := repo.CountAheadBehind(t.Context(), "main", "feature")
+	if err != nil {
+		t.Fatalf("CountAheadBehind() error = %v", err)
+	}
+	if ahead != 2 || behind != 1 {
+		t.Errorf("CountAheadBehind() = +%d/-%d, want +2/-1", ahead, behind)
+	}
+}
+
+func TestDetectDefaultBranch_FakeRunner(t *testing.T) {
+	t.Parallel()
+
+	// 主要なリモートとしてoriginが選ばれる場合の呼び出し
+	originRemote := func(f *fakeRunner) {
+		f.expect("remote").returns("origin")
+		f.expect("config", "--get-all", "checkout.defaultRemote").fails(1, "")
+		f.expect("config", "--get-all", "clone.defaultRemoteName").fails(1, "")
+	}
+	// origin/HEADがなく、リモートにも問い合わせられない場合の呼び出し
+	noOriginHEAD := func(f *fakeRunner) {
+		f.expect("symbolic-ref", "--quiet", "refs/remotes/origin/HEAD").fails(1, "")
+		f.expect("ls-remote", "--symref", "origin", "HEAD").fails(128, "fatal: Could not read from remote repository.")
+	}
+	noInitConfig := func(f *fakeRunner) {
+		f.expect("config", "--get-all", "init.defaultBranch").fails(1, "")
+	}
+
+	tests := []struct {
+		name         string
+		setup        func(f *fakeRunner)
+		want         string
+		wantRemote   string
+		wantStrategy DefaultBranchStrategy
+		wantRepaired bool
+		wantErr      error
+	}{
+		{
+			name: "origin/HEADから検出",
+			setup: func(f *fakeRunner) {
+				originRemote(f)
+				f.expect("symbolic-ref", "--quiet", "refs/remotes/origin/HEAD").returns("refs/remotes/origin/trunk")
+			},
+			want:         "trunk",
+			wantRemote:   "origin",
+			wantStrategy: DefaultBranchFromRemoteHEAD,
+		},
+		{
+			name: "スラッシュを含むブランチ名を切り詰めない",
+			setup: func(f *fakeRunner) {
+				originRemote(f)
+				f.expect("symbolic-ref", "--quiet", "refs/remotes/origin/HEAD").returns("refs/remotes/origin/release/main")
+			},
+			want:         "release/main",
+			wantRemote:   "origin",
+			wantStrategy: DefaultBranchFromRemoteHEAD,
+		},
+		{
+			name: "origin以外のリモートしかない場合はそのリモートのHEADから検出",
+			setup: func(f *fakeRunner) {
+				f.expect("remote").returns("upstream")
+				f.expect("config", "--get-all", "checkout.defaultRemote").fails(1, "")
+				f.expect("config", "--get-all", "clone.defaultRemoteName").fails(1, "")
+				f.expect("symbolic-ref", "--quiet", "refs/remotes/upstream/HEAD").returns("refs/remotes/upstream/trunk/v2")
+			},
+			want:         "trunk/v2",
+			wantRemote:   "upstream",
+			wantStrategy: DefaultBranchFromRemoteHEAD,
+		},
+		{
+			name: "checkout.defaultRemoteで指定されたリモートを優先",
+			setup: func(f *fakeRunner) {
+				f.expect("remote").returns("fork\norigin\nupstream")
+				f.expect("config", "--get-all", "checkout.defaultRemote").returns("upstream")
+				f.expect("symbolic-ref", "--quiet", "refs/remotes/upstream/HEAD").returns("refs/remotes/upstream/main")
+			},
+			want:         "main",
+			wantRemote:   "upstream",
+			wantStrategy: DefaultBranchFromRemoteHEAD,
+		},
+		{
+			name: "origin/HEADがない場合はリモートに問い合わせて修復",
+			setup: func(f *fakeRunner) {
+				originRemote(f)
+				f.expect("symbolic-ref", "--quiet", "refs/remotes/origin/HEAD").fails(1, "")
+				f.expect("ls-remote", "--symref", "origin", "HEAD").returns("ref: refs/heads/release/main\tHEAD\n0123456789abcdef\tHEAD")
+				f.expect("remote", "set-head", "origin", "release/main")
+			},
+			want:         "release/main",
+			wantRemote:   "origin",
+			wantStrategy: DefaultBranchFromRemoteQuery,
+			wantRepaired: true,
+		},
+		{
+			name: "リモートHEADがない場合はinit.defaultBranchを確認",
+			setup: func(f *fakeRunner) {
+				originRemote(f)
+				noOriginHEAD(f)
+				f.expect("config", "--get-all", "init.defaultBranch").returns("stable")
+				f.expect("rev-parse", "--verify", "--quiet", "refs/heads/stable").fails(1, "")
+				f.expect("rev-parse", "--verify", "--quiet", "refs/remotes/origin/stable").returns("abc")
+			},
+			want:         "stable",
+			wantRemote:   "origin",
+			wantStrategy: DefaultBranchFromInitConfig,
+		},
+		{
+			name: "symbolic-refが失敗した場合はローカルブランチから検出",
+			setup: func(f *fakeRunner) {
+				originRemote(f)
+				noOriginHEAD(f)
+				noInitConfig(f)
+				f.expect("branch", "--format=%(refname:short)").returns("feature\nmaster\n\n")
+			},
+			want:         "master",
+			wantRemote:   "origin",
+			wantStrategy: DefaultBranchFromLocalBranch,
+		},
+		{
+			name: "ローカルにない場合はリモートブランチから検出",
+			setup: func(f *fakeRunner) {
+				originRemote(f)
+				noOriginHEAD(f)
+				noInitConfig(f)
+				f.expect("branch", "--format=%(refname:short)").returns("feature")
+				f.expect("branch", "-r", "--format=%(refname:short)").returns("origin/feature/main\norigin/develop")
+			},
+			want:         "develop",
+			wantRemote:   "origin",
+			wantStrategy: DefaultBranchFromRemoteBranch,
+		},
+		{
+			name: "リモートがない場合はローカルブランチのみ確認",
+			setup: func(f *fakeRunner) {
+				f.expect("remote").returns("")
+				noInitConfig(f)
+				f.expect("branch", "--format=%(refname:short)").returns("feature\nmain")
+			},
+			want:         "main",
+			wantStrategy: DefaultBranchFromLocalBranch,
+		},
+		{
+			name: "どこにも見つからない場合",
+			setup: func(f *fakeRunner) {
+				originRemote(f)
+				noOriginHEAD(f)
+				noInitConfig(f)
+				f.expect("branch", "--format=%(refname:short)").returns("")
+				f.expect("branch", "-r", "--format=%(refname:short)").fails(1, "error: unknown remote")
+			},
+			wantErr: ErrNoDefaultBranch,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFakeRunner(t)
+			tt.setup(f)
+
+			got, err := f.repository().ResolveDefaultBranch(t.Context())
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ResolveDefaultBranch() error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			want := DefaultBranch{Name: tt.want, Remote: tt.wantRemote, Strategy: tt.wantStrategy, Repaired: tt.wantRepaired}
+			if *got != want {
+				t.Errorf("ResolveDefaultBranch() = %+v, want %+v", *got, want)
+			}
+		})
+	}
+}
+
+func TestListBranchInfos_FakeRunner(t *testing.T) {
+	t.Parallel()
+
+	format := "--format=%(refname:short)%09%(objectname)%09%(committerdate:unix)%09%(upstream:short)%09%(upstream:track)%09%(subject)"
+
+	tests := []struct {
+		name    string
+		output  string
+		want    []BranchInfo
+		wantErr bool
+	}{
+		{
+			name:   "件名にタブを含む",
+			output: "feature\tabc123\t1700000000\torigin/feature\t[gone]\tfix:\tthing\n\n",
+			want: []BranchInfo{{
+				Name:          "feature",
+				SHA:           "abc123",
+				CommitTime:    time.Unix(1700000000, 0).UTC(),
+				Upstream:      "origin/feature",
+				UpstreamTrack: "[gone]",
+				Subject:       "fix:\tthing",
+			}},
+		},
+		{
+			name:    "フィールドが不足している",
+			output:  "feature\tabc123",
+			wantErr: true,
+		},
+		{
+			name:    "コミット日時が数値でない",
+			output:  "feature\tabc123\tyesterday\t\t\tsubject",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFakeRunner(t)
+			f.expect("for-each-ref", format, "refs/heads").returns(tt.output)
+
+			got, err := f.repository().ListBranchInfos(t.Context())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ListBranchInfos() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ListBranchInfos() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetHead(t *testing.T) {
+	t.Parallel()
+
+	const sha = "0123456789abcdef0123456789abcdef01234567"
+	tests := []struct {
+		name       string
+		setup      func(f *fakeRunner)
+		want       HeadState
+		wantBranch string
+		wantErr    error
+	}{
+		{
+			name: "ブランチをチェックアウトしている場合",
+			setup: func(f *fakeRunner) {
+				f.expect("symbolic-ref", "--quiet", "--short", "HEAD").returns("feature")
+				f.expect("rev-parse", "--verify", "--quiet", "HEAD").returns(sha)
+			},
+			want:       HeadState{Branch: "feature", Commit: sha},
+			wantBranch: "feature",
+		},
+		{
+			name: "detached HEADの場合はブランチ名を\"HEAD\"として扱わない",
+			setup: func(f *fakeRunner) {
+				f.expect("symbolic-ref", "--quiet", "--short", "HEAD").fails(1, "")
+				f.expect("rev-parse", "--verify", "--quiet", "HEAD").returns(sha)
+			},
+			want:    HeadState{Commit: sha, Detached: true},
+			wantErr: ErrDetachedHead,
+		},
+		{
+			name: "まだコミットがないブランチの場合",
+			setup: func(f *fakeRunner) {
+				f.expect("symbolic-ref", "--quiet", "--short", "HEAD").returns("main")
+				f.expect("rev-parse", "--verify", "--quiet", "HEAD").fails(1, "")
+			},
+			want:       HeadState{Branch: "main"},
+			wantBranch: "main",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			f := newFakeRunner(t)
+			// GetHeadとGetCurrentBranchで同じコマンドが2回呼び出される
+			tt.setup(f)
+			tt.setup(f)
+			repo := f.repository()
+
+			got, err := repo.GetHead(t.Context())
+			if err != nil {
+				t.Fatalf("GetHead() error = %v", err)
+			}
+			if *got != tt.want {
+				t.Errorf("GetHead() = %+v, want %+v", *got, tt.want)
+			}
+
+			branch, err := repo.GetCurrentBranch(t.Context())
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("GetCurrentBranch() error = %v, want %v", err, tt.wantErr)
+			}
+			if branch != tt.wantBranch {
+				t.Errorf("GetCurrentBranch() = %q, want %q", branch, tt.wantBranch)
+			}
+		})
+	}
+}
@@ -1,28 +1,53 @@
 package git
 
 import (
+	"context"
 	"fmt"
 	"log"
+	"strings"
 )
 
 // CleanupOptions はクリーンアップ処理のオプションを表します
 type CleanupOptions struct {
-	DryRun        bool   // 実行のシミュレーションのみ
-	Verbose       bool   // 詳細ログの表示
-	Yes           bool   // 確認プロンプトのスキップ
-	Force         bool   // 強制実行（未マージブランチも削除）
-	DefaultBranch string // 手動指定のデフォルトブランチ
-	ExcludePattern string // 除外パターン
-	NoPull        bool   // プル処理のスキップ
+	DryRun          bool     // 実行のシミュレーションのみ
+	Verbose         bool     // 詳細ログの表示
+	Yes             bool     // 確認プロンプトのスキップ
+	Force           bool     // 強制実行（未マージブランチも削除）
+	DefaultBranch   string   // 手動指定のデフォルトブランチ
+	ExcludePattern  string   // 除外パターン
+	ExcludePatterns []string // 除外パターン（複数指定、グロブまたは "re:" で始まる正規表現）
+	OnlyPatterns    []string // 対象とするブランチのパターン（指定時はマッチしたブランチのみ削除対象）
+	ProtectPatterns []string // 設定ファイルなどで指定された保護ブランチのパターン（gitc.protect と .gitcprotect に追加）
+	NoPull          bool     // プル処理のスキップ（Pull に none を指定した場合と同じ）
+	Pull            string   // プルの方法（ff-only, rebase, merge, none。空の場合はff-only）
+	Interactive     bool     // ブランチごとに削除するか対話的に選択
+	Gone            bool     // 上流ブランチがリモートから削除されたブランチのみを対象にする
+	RemoveWorktrees bool     // マージ済みブランチをチェックアウトしている変更のないワークツリーを削除する
+	Dirty           string   // 未コミットの変更がある場合の扱い（abort, stash, keep。空の場合はabort）
+	Return          bool     // クリーンアップ後、元のブランチが残っていればそのブランチに戻る
+	BaseRemote      string   // マージ済みの判定に使用するリモート（空の場合は upstream があればupstream、なければ主要なリモート）
+	PushRemote      string   // 自分のブランチをプッシュするリモート（空の場合はベース以外の origin、なければベース）
+	SyncFork        bool     // フォークのデフォルトブランチをベースリモートから早送りし、プッシュ先にプッシュする
 }
 
 // CleanupResult はクリーンアップ処理の結果を表します
 type CleanupResult struct {
-	DefaultBranch    string   // 検出されたデフォルトブランチ
-	DeletedBranches  []string // 削除されたブランチのリスト
-	SkippedBranches  []string // スキップされたブランチのリスト
-	Errors          []error  // 発生したエラーのリスト
-	WasDryRun       bool     // ドライランモードだったかどうか
+	DefaultBranch     string           // 検出されたデフォルトブランチ
+	DeletedBranches   []string         // 削除されたブランチのリスト
+	SkippedBranches   []string         // スキップされたブランチのリスト
+	ProtectedBranches []string         // 保護ブランチとしてスキップされたブランチのリスト
+	Errors            []error          // 発生したエラーのリスト
+	WasDryRun         bool             // ドライランモードだったかどうか
+	RemovedWorktrees  []string         // 削除されたワークツリーのリスト
+	CheckedOut        bool             // デフォルトブランチに切り替えたかどうか
+	Pulled            bool             // デフォルトブランチをプルしたかどうか
+	StashRef          string           // 自動スタッシュの参照（例: stash@{0}。作成しなかった場合は空）
+	StashRestored     bool             // 自動スタッシュを元のブランチに再適用したかどうか
+	ReturnedTo        string           // クリーンアップ後に戻ったブランチ（detached HEADの場合はコミット）
+	DetachedFrom      string           // detached HEADから切り替えた場合の元のコミット
+	MergeTarget       string           // マージ済みの判定に使用したブランチ（フォーク運用では upstream/main など）
+	ForkSynced        string           // フォークを同期した場合のプッシュ先のリモート（同期しなかった場合は空）
+	Decisions         []BranchDecision // ブランチごとの判定結果（ドライランでは実行予定の内容）
 }
 
 // Validate はオプションの妥当性をチェックします
@@ -30,156 +55,138 @@ func (opts *CleanupOptions) Validate() error {
 	if opts.DryRun && opts.Force {
 		return fmt.Errorf("--dry-run and --force cannot be used together")
 	}
-	return nil
-}
-
-// ExecuteCleanup はメインのクリーンアップ処理を実行します
-func ExecuteCleanup(options CleanupOptions) (*CleanupResult, error) {
-	// verboseログ出力用のヘルパー関数
-	logVerbose := func(format string, args ...interface{}) {
-		if options.Verbose {
-			log.Printf("[VERBOSE] "+format, args...)
+	if opts.Interactive && opts.Yes {
+		return fmt.Errorf("--interactive and --yes cannot be used together")
+	}
+	for _, pattern := range opts.excludePatterns() {
+		if strings.TrimSpace(pattern) == "" {
+			return fmt.Errorf("--exclude requires a non-empty pattern")
+		}
+		if opts.DefaultBranch != "" && pattern == opts.DefaultBranch {
+			return fmt.Errorf("--exclude %q: the default branch is never deleted and cannot be excluded", pattern)
 		}
 	}
-
-	// オプションのバリデーション
-	if err := options.Validate(); err != nil {
-		return nil, err
+	if _, err := ParseBranchPatterns(opts.excludePatterns()); err != nil {
+		return fmt.Errorf("--exclude: %w", err)
 	}
-
-	logVerbose("クリーンアップ処理を開始します")
-	logVerbose("オプション: DryRun=%t, Verbose=%t, Yes=%t, Force=%t", options.DryRun, options.Verbose, options.Yes, options.Force)
-
-	result := &CleanupResult{
-		WasDryRun: options.DryRun,
+	if _, err := ParseBranchPatterns(opts.OnlyPatterns); err != nil {
+		return fmt.Errorf("--only: %w", err)
 	}
-
-	// 1. Gitリポジトリかどうかの確認
-	logVerbose("Gitリポジトリの確認を開始")
-	cwd, err := GetCurrentDirectory()
-	if err != nil {
-		return nil, NewGitError("cleanup", err)
+	if _, err := ParseDirtyPolicy(opts.Dirty); err != nil {
+		return fmt.Errorf("--dirty: %w", err)
 	}
-	logVerbose("現在のディレクトリ: %s", cwd)
-
-	if err := IsGitRepository(cwd); err != nil {
-		return nil, NewGitError("cleanup", ErrNotGitRepository).WithPath(cwd)
+	if _, err := ParsePullStrategy(opts.Pull); err != nil {
+		return fmt.Errorf("--pull: %w", err)
 	}
-	logVerbose("Gitリポジトリであることを確認")
+	return nil
+}
 
-	// 2. デフォルトブランチの検出
-	logVerbose("デフォルトブランチの検出を開始")
-	var defaultBranch string
-	if options.DefaultBranch != "" {
-		// 手動指定されたブランチの存在確認
-		exists, err := BranchExists(options.DefaultBranch)
-		if err != nil {
-			return nil, NewGitError("cleanup", err).WithMessage("failed to check branch existence")
-		}
-		if !exists {
-			return nil, NewGitError("cleanup", fmt.Errorf("specified branch '%s' does not exist", options.DefaultBranch))
-		}
-		defaultBranch = options.DefaultBranch
-		logVerbose("手動指定されたデフォルトブランチ: %s", defaultBranch)
-	} else {
-		defaultBranch, err = DetectDefaultBranch()
-		if err != nil {
-			return nil, NewGitError("cleanup", err)
-		}
-		logVerbose("検出されたデフォルトブランチ: %s", defaultBranch)
+// pullStrategy はNoPullとPullをまとめたプルの方法を返します（Validate済みであること）
+func (opts *CleanupOptions) pullStrategy() PullStrategy {
+	if opts.NoPull {
+		return PullNone
 	}
-	result.DefaultBranch = defaultBranch
+	strategy, _ := ParsePullStrategy(opts.Pull)
+	return strategy
+}
 
-	// 3. デフォルトブランチへの切り替え
-	logVerbose("現在のブランチ確認と切り替えを開始")
-	currentBranch, err := GetCurrentBranch()
-	if err != nil {
-		return nil, NewGitError("cleanup", err)
+// excludePatterns はExcludePatternとExcludePatternsをまとめた除外パターンの一覧を返します
+func (opts *CleanupOptions) excludePatterns() []string {
+	var patterns []string
+	if opts.ExcludePattern != "" {
+		patterns = append(patterns, opts.ExcludePattern)
 	}
-	logVerbose("現在のブランチ: %s", currentBranch)
+	return append(patterns, opts.ExcludePatterns...)
+}
 
-	if currentBranch != defaultBranch {
-		if options.DryRun {
-			logVerbose("ドライランモード: ブランチ切り替えをシミュレーション (%s -> %s)", currentBranch, defaultBranch)
-		} else {
-			logVerbose("デフォルトブランチに切り替え: %s -> %s", currentBranch, defaultBranch)
-			if err := CheckoutBranch(defaultBranch); err != nil {
-				return nil, NewGitError("cleanup", err).WithMessage("failed to switch to default branch")
-			}
-			logVerbose("ブランチ切り替え完了")
-		}
-	} else {
-		logVerbose("すでにデフォルトブランチにいます")
-	}
+// branchFilter は解析済みの保護・除外・対象パターンを表します
+type branchFilter struct {
+	protected []*BranchPattern
+	exclude   []*BranchPattern
+	only      []*BranchPattern
+}
 
-	// 4. フェッチ処理（必須・ドライランでも実行）
-	logVerbose("フェッチ処理を開始 (git fetch --all --prune)")
-	if err := Fetch(); err != nil {
-		// フェッチ失敗は警告として扱い、処理を継続
-		logVerbose("フェッチエラー: %v", err)
-		result.Errors = append(result.Errors, NewGitError("cleanup", err).WithMessage("fetch failed"))
-	} else {
-		logVerbose("フェッチ完了")
+// newBranchFilter はオプションと保護ブランチのパターンを解析してフィルタを作成します
+func newBranchFilter(opts CleanupOptions, protectedPatterns []string) (*branchFilter, error) {
+	protected, err := ParseBranchPatterns(protectedPatterns)
+	if err != nil {
+		return nil, fmt.Errorf("protected branches: %w", err)
 	}
-
-	if options.DryRun {
-		// ドライランモードの場合はfetch以外の実際の処理は行わない
-		logVerbose("ドライランモードのため、フェッチ以外の処理をスキップ")
-		return result, nil
+	exclude, err := ParseBranchPatterns(opts.excludePatterns())
+	if err != nil {
+		return nil, err
 	}
-
-	// 5. プル処理（--no-pullが指定されていない場合）
-	if !options.NoPull {
-		logVerbose("プル処理を開始 (git pull)")
-		if err := Pull(); err != nil {
-			// プル失敗は警告として扱い、処理を継続
-			logVerbose("プルエラー: %v", err)
-			result.Errors = append(result.Errors, NewGitError("cleanup", err).WithMessage("pull failed"))
-		} else {
-			logVerbose("プル完了")
-		}
-	} else {
-		logVerbose("プル処理をスキップ (--no-pull指定)")
+	only, err := ParseBranchPatterns(opts.OnlyPatterns)
+	if err != nil {
+		return nil, err
 	}
+	return &branchFilter{protected: protected, exclude: exclude, only: only}, nil
+}
 
-	// 6. ローカルブランチの一覧取得
-	logVerbose("ローカルブランチ一覧を取得")
-	branches, err := ListLocalBranches()
+// ExecuteCleanup はメインのクリーンアップ処理を実行します
+// 実行計画を作成し、ドライランでなければその計画を適用します
+func (r *Repository) ExecuteCleanup(ctx context.Context, options CleanupOptions) (*CleanupResult, error) {
+	plan, err := r.PlanCleanup(ctx, options)
 	if err != nil {
-		return nil, NewGitError("cleanup", err)
+		return nil, err
 	}
-	logVerbose("検出されたブランチ: %v", branches)
 
-	// 7. ブランチの削除
-	logVerbose("ブランチ削除処理を開始")
-	for _, branch := range branches {
-		if branch == defaultBranch {
-			// デフォルトブランチはスキップ
-			logVerbose("デフォルトブランチをスキップ: %s", branch)
-			result.SkippedBranches = append(result.SkippedBranches, branch)
-			continue
-		}
+	return r.ApplyCleanupPlan(ctx, plan, options)
+}
 
-		// 除外パターンのチェック（簡単な実装）
-		if options.ExcludePattern != "" && branch == options.ExcludePattern {
-			logVerbose("除外パターンにマッチするためスキップ: %s", branch)
-			result.SkippedBranches = append(result.SkippedBranches, branch)
-			continue
+// newVerboseLogger はverboseログ出力用の関数を返します
+func newVerboseLogger(verbose bool) func(format string, args ...interface{}) {
+	return func(format string, args ...interface{}) {
+		if verbose {
+			log.Printf("[VERBOSE] "+format, args...)
 		}
+	}
+}
 
-		// ブランチ削除
-		logVerbose("ブランチ削除を試行: %s", branch)
-		if err := DeleteBranch(branch, options.Force); err != nil {
-			logVerbose("ブランチ削除エラー: %s - %v", branch, err)
-			result.Errors = append(result.Errors, NewGitError("cleanup", err).WithPath(branch))
-			result.SkippedBranches = append(result.SkippedBranches, branch)
-		} else {
-			logVerbose("ブランチ削除成功: %s", branch)
-			result.DeletedBranches = append(result.DeletedBranches, branch)
-		}
+// classifyBranch はブランチを削除するかどうかを判定します
+// マージ状態の理由にはtarget（フォーク運用ではフォーク元のデフォルトブランチ）を表示します
+func classifyBranch(branch BranchInfo, defaultBranch, target string, status MergeStatus, filter *branchFilter, options CleanupOptions) BranchDecision {
+	decision := BranchDecision{Branch: branch.Name, Action: BranchActionSkip}
+	protected := matchBranchPatterns(branch.Name, filter.protected)
+	excluded := matchBranchPatterns(branch.Name, filter.exclude)
+
+	reason := fmt.Sprintf("%s into %s", status, target)
+	if status == MergeStatusUnmerged {
+		reason = fmt.Sprintf("not merged into %s", target)
+	}
+	if branch.IsUpstreamGone() {
+		reason = "upstream gone, " + reason
 	}
 
-	logVerbose("クリーンアップ処理完了 - 削除: %d, スキップ: %d, エラー: %d", len(result.DeletedBranches), len(result.SkippedBranches), len(result.Errors))
+	switch {
+	case branch.Name == defaultBranch:
+		decision.Reason = "default branch"
+		decision.Locked = true
+	case protected != nil:
+		decision.Reason = fmt.Sprintf("protected branch (matches %q)", protected)
+		decision.Locked = true
+		decision.Protected = true
+	case excluded != nil:
+		decision.Reason = fmt.Sprintf("matches exclude pattern %q", excluded)
+		decision.Locked = true
+	case len(filter.only) > 0 && matchBranchPatterns(branch.Name, filter.only) == nil:
+		decision.Reason = "does not match any --only pattern"
+	case options.Gone && !branch.IsUpstreamGone():
+		decision.Reason = "upstream not gone (--gone)"
+	case status == MergeStatusMerged:
+		// リモート追跡ブランチにのみマージ済みの場合も、プル後に git branch -d で確認してから削除する
+		decision.Action = BranchActionDelete
+		decision.Reason = reason
+	case status == MergeStatusSquashMerged || status == MergeStatusRebaseMerged:
+		// git branch -d は拒否するが、マージ済みであることが確認できているため強制削除する
+		decision.Action = BranchActionForceDelete
+		decision.Reason = reason
+	case options.Force:
+		decision.Action = BranchActionForceDelete
+		decision.Reason = reason + " (--force)"
+	default:
+		decision.Reason = reason
+	}
 
-	return result, nil
-}
\ No newline at end of file
+	return decision
+}
@@ -0,0 +1,87 @@
+package git
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPlanCleanup_UpstreamGone(t *testing.T) {
+	t.Parallel()
+
+	dir, cleanup := createTestGitRepo(t)
+	defer cleanup()
+	repo := NewRepository(dir)
+	addTestRemote(t, dir)
+
+	// マージ済みで上流が削除されたブランチ
+	runGit(t, dir, "branch", "gone-merged")
+	runGit(t, dir, "push", "-u", "origin", "gone-merged")
+	runGit(t, dir, "push", "origin", "--delete", "gone-merged")
+
+	// 未マージで上流が削除されたブランチ
+	runGit(t, dir, "checkout", "-b", "gone-unmerged")
+	runGit(t, dir, "commit", "--allow-empty", "-m", "squashed on remote")
+	runGit(t, dir, "push", "-u", "origin", "gone-unmerged")
+	runGit(t, dir, "push", "origin", "--delete", "gone-unmerged")
+	runGit(t, dir, "checkout", "main")
+
+	// マージ済みで上流が残っているブランチ
+	runGit(t, dir, "branch", "alive-merged")
+	runGit(t, dir, "push", "-u", "origin", "alive-merged")
+
+	tests := []struct {
+		name    string
+		options CleanupOptions
+		want    map[string]BranchAction
+	}{
+		{
+			name:    "通常モード",
+			options: CleanupOptions{NoPull: true},
+			want: map[string]BranchAction{
+				"gone-merged":   BranchActionDelete,
+				"gone-unmerged": BranchActionSkip,
+				"alive-merged":  BranchActionDelete,
+			},
+		},
+		{
+			name:    "--goneモード",
+			options: CleanupOptions{NoPull: true, Gone: true},
+			want: map[string]BranchAction{
+				"gone-merged":   BranchActionDelete,
+				"gone-unmerged": BranchActionSkip,
+				"alive-merged":  BranchActionSkip,
+			},
+		},
+		{
+			name:    "--goneモードで強制削除",
+			options: CleanupOptions{NoPull: true, Gone: true, Force: true},
+			want: map[string]BranchAction{
+				"gone-merged":   BranchActionDelete,
+				"gone-unmerged": BranchActionForceDelete,
+				"alive-merged":  BranchActionSkip,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan, err := repo.PlanCleanup(t.Context(), tt.options)
+			if err != nil {
+				t.Fatalf("PlanCleanup() error = %v", err)
+			}
+
+			for _, decision := range plan.Branches {
+				want, ok := tt.want[decision.Branch]
+				if !ok {
+					continue
+				}
+				if decision.Action != want {
+					t.Errorf("Decision for %s = %s (%s), want %s", decision.Branch, decision.Action, decision.Reason, want)
+				}
+				if strings.HasPrefix(decision.Branch, "gone-") && !strings.Contains(decision.Reason, "upstream gone") {
+					t.Errorf("Reason for %s = %q, want to mention upstream gone", decision.Branch, decision.Reason)
+				}
+			}
+		})
+	}
+}
@@ -16,21 +16,21 @@ func TestCleanupIntegrationFlow(t *testing.T) {
 	t.Run("完全なクリーンアップフロー", func(t *testing.T) {
 		dir, cleanup := createTestGitRepo(t)
 		defer cleanup()
-		defer changeDir(t, dir)()
+		repo := NewRepository(dir)
 
 		// テスト用ブランチを複数作成
 		branches := []string{"feature/test1", "feature/test2", "bugfix/issue123"}
 		for _, branch := range branches {
-			_, err := ExecuteCommand("checkout", "-b", branch)
+			_, err := repo.ExecuteCommand(t.Context(), "checkout", "-b", branch)
 			if err != nil {
 				t.Fatalf("Failed to create branch %s: %v", branch, err)
 			}
 		}
 
 		// mainブランチに戻る
-		if err := CheckoutBranch("main"); err != nil {
+		if err := repo.CheckoutBranch(t.Context(), "main"); err != nil {
 			// masterブランチを試す
-			if err := CheckoutBranch("master"); err != nil {
+			if err := repo.CheckoutBranch(t.Context(), "master"); err != nil {
 				t.Fatalf("Failed to checkout main/master branch: %v", err)
 			}
 		}
@@ -43,7 +43,7 @@ func TestCleanupIntegrationFlow(t *testing.T) {
 			NoPull:  true, // テスト環境ではプルをスキップ
 		}
 
-		result, err := ExecuteCleanup(options)
+		result, err := repo.ExecuteCleanup(t.Context(), options)
 		if err != nil {
 			t.Fatalf("ExecuteCleanup() failed: %v", err)
 		}
@@ -59,7 +59,7 @@ func TestCleanupIntegrationFlow(t *testing.T) {
 		}
 
 		// 現在のブランチがデフォルトブランチになっていることを確認
-		currentBranch, err := GetCurrentBranch()
+		currentBranch, err := repo.GetCurrentBranch(t.Context())
 		if err != nil {
 			t.Fatalf("Failed to get current branch: %v", err)
 		}
@@ -72,16 +72,16 @@ func TestCleanupIntegrationFlow(t *testing.T) {
 	t.Run("処理順序の確認", func(t *testing.T) {
 		dir, cleanup := createTestGitRepo(t)
 		defer cleanup()
-		defer changeDir(t, dir)()
+		repo := NewRepository(dir)
 
 		// テスト用ブランチを作成し、そのブランチにいる状態でクリーンアップを実行
-		_, err := ExecuteCommand("checkout", "-b", "test-branch")
+		_, err := repo.ExecuteCommand(t.Context(), "checkout", "-b", "test-branch")
 		if err != nil {
 			t.Fatalf("Failed to create test branch: %v", err)
 		}
 
 		// 現在のブランチを確認
-		currentBranch, err := GetCurrentBranch()
+		currentBranch, err := repo.GetCurrentBranch(t.Context())
 		if err != nil {
 			t.Fatalf("Failed to get current branch: %v", err)
 		}
@@ -97,13 +97,13 @@ func TestCleanupIntegrationFlow(t *testing.T) {
 			NoPull: true,
 		}
 
-		result, err := ExecuteCleanup(options)
+		result, err := repo.ExecuteCleanup(t.Context(), options)
 		if err != nil {
 			t.Fatalf("ExecuteCleanup() failed: %v", err)
 		}
 
 		// クリーンアップ後、デフォルトブランチに切り替わっていることを確認
-		finalBranch, err := GetCurrentBranch()
+		finalBranch, err := repo.GetCurrentBranch(t.Context())
 		if err != nil {
 			t.Fatalf("Failed to get final branch: %v", err)
 		}
@@ -129,7 +129,7 @@ func TestCleanupIntegrationFlow(t *testing.T) {
 	t.Run("エラーハンドリング", func(t *testing.T) {
 		dir, cleanup := createTestGitRepo(t)
 		defer cleanup()
-		defer changeDir(t, dir)()
+		repo := NewRepository(dir)
 
 		// 存在しないデフォルトブランチを指定
 		options := CleanupOptions{
@@ -139,7 +139,7 @@ func TestCleanupIntegrationFlow(t *testing.T) {
 			NoPull:        true,
 		}
 
-		_, err := ExecuteCleanup(options)
+		_, err := repo.ExecuteCleanup(t.Context(), options)
 		if err == nil {
 			t.Error("Expected error when specifying nonexistent default branch")
 		}
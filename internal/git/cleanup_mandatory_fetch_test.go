@@ -38,11 +38,10 @@ func TestMandatoryFetch(t *testing.T) {
 			// テスト用のGitリポジトリをセットアップ
 			repoPath, cleanup := createTestGitRepo(t)
 			defer cleanup()
-			restoreDir := changeDir(t, repoPath)
-			defer restoreDir()
+			repo := NewRepository(repoPath)
 
 			// テスト実行
-			result, err := ExecuteCleanup(tt.options)
+			result, err := repo.ExecuteCleanup(t.Context(), tt.options)
 			
 			// エラーは許可（リモート接続エラーなど）
 			// ここではfetchが実行されたかどうかを確認
@@ -76,10 +75,9 @@ func TestFetchProcessOrder(t *testing.T) {
 
 	repoPath, cleanup := createTestGitRepo(t)
 	defer cleanup()
-	restoreDir := changeDir(t, repoPath)
-	defer restoreDir()
+	repo := NewRepository(repoPath)
 
-	result, err := ExecuteCleanup(options)
+	result, err := repo.ExecuteCleanup(t.Context(), options)
 	
 	// ドライランではfetchは実行されるが、他の操作はシミュレーション
 	if err != nil {
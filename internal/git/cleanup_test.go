@@ -1,6 +1,7 @@
 package git
 
 import (
+	"reflect"
 	"testing"
 )
 
@@ -57,9 +58,9 @@ func TestExecuteCleanup(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			dir := tt.setup(t)
-			defer changeDir(t, dir)()
+			repo := NewRepository(dir)
 
-			result, err := ExecuteCleanup(tt.options)
+			result, err := repo.ExecuteCleanup(t.Context(), tt.options)
 
 			if (err != nil) != tt.wantErr {
 				t.Errorf("ExecuteCleanup() error = %v, wantErr %v", err, tt.wantErr)
@@ -107,6 +108,37 @@ func TestCleanupOptions_Validate(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "対話モードと確認スキップの組み合わせ（無効）",
+			options: CleanupOptions{
+				Interactive: true,
+				Yes:         true,
+			},
+			wantErr: true,
+		},
+		{
+			name: "空の除外パターン（無効）",
+			options: CleanupOptions{
+				ExcludePatterns: []string{"feature", ""},
+			},
+			wantErr: true,
+		},
+		{
+			name: "デフォルトブランチを除外（無効）",
+			options: CleanupOptions{
+				DefaultBranch:   "develop",
+				ExcludePatterns: []string{"develop"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "複数の除外パターン",
+			options: CleanupOptions{
+				ExcludePattern:  "keep-me",
+				ExcludePatterns: []string{"feature", "hotfix"},
+			},
+			wantErr: false,
+		},
 	}
 
 	for _, tt := range tests {
@@ -116,4 +148,200 @@ func TestCleanupOptions_Validate(t *testing.T) {
 			}
 		})
 	}
-}
\ No newline at end of file
+}
+
+func TestExecuteCleanup_DryRunPlan(t *testing.T) {
+	t.Parallel()
+
+	dir, cleanup := createTestGitRepo(t)
+	defer cleanup()
+	repo := NewRepository(dir)
+
+	// マージ済みブランチと未マージブランチを作成
+	runGit(t, dir, "branch", "merged-branch")
+	runGit(t, dir, "checkout", "-b", "unmerged-branch")
+	runGit(t, dir, "commit", "--allow-empty", "-m", "unmerged work")
+	runGit(t, dir, "checkout", "main")
+
+	result, err := repo.ExecuteCleanup(t.Context(), CleanupOptions{DryRun: true, Yes: true})
+	if err != nil {
+		t.Fatalf("ExecuteCleanup() error = %v", err)
+	}
+
+	want := map[string]BranchAction{
+		"main":            BranchActionSkip,
+		"merged-branch":   BranchActionDelete,
+		"unmerged-branch": BranchActionSkip,
+	}
+	if len(result.Decisions) != len(want) {
+		t.Fatalf("Decisions = %v, want %d entries", result.Decisions, len(want))
+	}
+	for _, decision := range result.Decisions {
+		if decision.Action != want[decision.Branch] {
+			t.Errorf("Decision for %s = %s, want %s", decision.Branch, decision.Action, want[decision.Branch])
+		}
+		if decision.Reason == "" {
+			t.Errorf("Decision for %s has empty reason", decision.Branch)
+		}
+	}
+
+	if len(result.DeletedBranches) != 1 || result.DeletedBranches[0] != "merged-branch" {
+		t.Errorf("DeletedBranches = %v, want [merged-branch]", result.DeletedBranches)
+	}
+
+	// ドライランでは実際には削除されていないこと
+	branches, err := repo.ListLocalBranches(t.Context())
+	if err != nil {
+		t.Fatalf("ListLocalBranches() error = %v", err)
+	}
+	if len(branches) != 3 {
+		t.Errorf("ListLocalBranches() = %v, want 3 branches to remain", branches)
+	}
+}
+
+func TestPlanCleanup_Patterns(t *testing.T) {
+	t.Parallel()
+
+	dir, cleanup := createTestGitRepo(t)
+	defer cleanup()
+	repo := NewRepository(dir)
+
+	for _, branch := range []string{"release/1.0", "hotfix-1", "feature/JIRA-1", "feature/misc"} {
+		runGit(t, dir, "branch", branch)
+	}
+
+	tests := []struct {
+		name       string
+		options    CleanupOptions
+		wantDelete []string
+		wantReason map[string]string
+	}{
+		{
+			name: "グロブと正規表現で除外",
+			options: CleanupOptions{
+				ExcludePatterns: []string{"release/*", "hotfix-*", `re:^feature/JIRA-\d+$`},
+			},
+			wantDelete: []string{"feature/misc"},
+			wantReason: map[string]string{
+				"release/1.0":    `matches exclude pattern "release/*"`,
+				"hotfix-1":       `matches exclude pattern "hotfix-*"`,
+				"feature/JIRA-1": `matches exclude pattern "re:^feature/JIRA-\\d+$"`,
+			},
+		},
+		{
+			name: "--onlyで対象を限定",
+			options: CleanupOptions{
+				OnlyPatterns: []string{"feature/*"},
+			},
+			wantDelete: []string{"feature/JIRA-1", "feature/misc"},
+			wantReason: map[string]string{
+				"release/1.0": "does not match any --only pattern",
+			},
+		},
+		{
+			name: "除外が対象より優先される",
+			options: CleanupOptions{
+				ExcludePatterns: []string{"feature/JIRA-*"},
+				OnlyPatterns:    []string{"feature/*"},
+			},
+			wantDelete: []string{"feature/misc"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.options.NoPull = true
+			plan, err := repo.PlanCleanup(t.Context(), tt.options)
+			if err != nil {
+				t.Fatalf("PlanCleanup() error = %v", err)
+			}
+
+			var deletes []string
+			for _, decision := range plan.Branches {
+				if decision.Action != BranchActionSkip {
+					deletes = append(deletes, decision.Branch)
+				}
+				if want, ok := tt.wantReason[decision.Branch]; ok && decision.Reason != want {
+					t.Errorf("Reason for %s = %q, want %q", decision.Branch, decision.Reason, want)
+				}
+			}
+			if !reflect.DeepEqual(deletes, tt.wantDelete) {
+				t.Errorf("deleted = %v, want %v", deletes, tt.wantDelete)
+			}
+		})
+	}
+}
+
+func TestCleanup_Return(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		setup      func(t *testing.T, dir string)
+		noReturn   bool
+		wantHEAD   string // 期待するブランチ名（"HEAD"の場合はdetached HEAD）
+		wantCommit bool   // detached HEADの場合に元のコミットに戻っているか確認する
+	}{
+		{
+			name: "未マージのブランチに戻る",
+			setup: func(t *testing.T, dir string) {
+				runGit(t, dir, "checkout", "-b", "feature")
+				runGit(t, dir, "commit", "--allow-empty", "-m", "feature work")
+			},
+			wantHEAD: "feature",
+		},
+		{
+			name: "削除されたブランチには戻らない",
+			setup: func(t *testing.T, dir string) {
+				runGit(t, dir, "checkout", "-b", "feature")
+			},
+			wantHEAD: "main",
+		},
+		{
+			name: "detached HEADの場合は元のコミットに戻る",
+			setup: func(t *testing.T, dir string) {
+				runGit(t, dir, "commit", "--allow-empty", "-m", "second")
+				runGit(t, dir, "checkout", "--detach", "HEAD~1")
+			},
+			wantHEAD:   "HEAD",
+			wantCommit: true,
+		},
+		{
+			name: "--return未指定の場合はデフォルトブランチに留まる",
+			setup: func(t *testing.T, dir string) {
+				runGit(t, dir, "checkout", "-b", "feature")
+				runGit(t, dir, "commit", "--allow-empty", "-m", "feature work")
+			},
+			noReturn: true,
+			wantHEAD: "main",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			dir, cleanup := createTestGitRepo(t)
+			defer cleanup()
+			tt.setup(t, dir)
+			original := runGit(t, dir, "rev-parse", "HEAD")
+
+			result, err := NewRepository(dir).ExecuteCleanup(t.Context(), CleanupOptions{NoPull: true, Return: !tt.noReturn})
+			if err != nil {
+				t.Fatalf("ExecuteCleanup() error = %v", err)
+			}
+			if len(result.Errors) != 0 {
+				t.Errorf("Errors = %v, want none", result.Errors)
+			}
+
+			if got := runGit(t, dir, "rev-parse", "--abbrev-ref", "HEAD"); got != tt.wantHEAD {
+				t.Errorf("HEAD = %s, want %s", got, tt.wantHEAD)
+			}
+			if tt.wantCommit {
+				if got := runGit(t, dir, "rev-parse", "HEAD"); got != original {
+					t.Errorf("HEAD commit = %s, want %s", got, original)
+				}
+			}
+		})
+	}
+}
@@ -47,11 +47,10 @@ func TestVerboseMode(t *testing.T) {
 			// テスト用のGitリポジトリをセットアップ
 			repoPath, cleanup := createTestGitRepo(t)
 			defer cleanup()
-			restoreDir := changeDir(t, repoPath)
-			defer restoreDir()
+			repo := NewRepository(repoPath)
 
 			// テスト実行
-			result, err := ExecuteCleanup(tt.options)
+			result, err := repo.ExecuteCleanup(t.Context(), tt.options)
 			
 			// エラーは許可（リモート接続エラーなど）
 			if err != nil {
@@ -106,8 +105,7 @@ func TestVerboseLogContent(t *testing.T) {
 	// テスト用のGitリポジトリをセットアップ
 	repoPath, cleanup := createTestGitRepo(t)
 	defer cleanup()
-	restoreDir := changeDir(t, repoPath)
-	defer restoreDir()
+	repo := NewRepository(repoPath)
 
 	options := CleanupOptions{
 		DryRun:  true,
@@ -115,7 +113,7 @@ func TestVerboseLogContent(t *testing.T) {
 		Yes:     true,
 	}
 
-	result, err := ExecuteCleanup(options)
+	result, err := repo.ExecuteCleanup(t.Context(), options)
 	
 	// エラーは許可
 	if err != nil {
@@ -139,7 +137,7 @@ func TestVerboseLogContent(t *testing.T) {
 
 	// 処理ステップがログに含まれているか確認
 	expectedSteps := []string{
-		"現在のディレクトリ:",
+		"対象ディレクトリ:",
 		"検出されたデフォルトブランチ:",
 		"現在のブランチ:",
 		"git fetch --all --prune",
@@ -161,8 +159,7 @@ func TestVerboseWithNormalMode(t *testing.T) {
 
 	repoPath, cleanup := createTestGitRepo(t)
 	defer cleanup()
-	restoreDir := changeDir(t, repoPath)
-	defer restoreDir()
+	repo := NewRepository(repoPath)
 
 	// verbose無効での実行
 	options1 := CleanupOptions{
@@ -171,7 +168,7 @@ func TestVerboseWithNormalMode(t *testing.T) {
 		Yes:     true,
 	}
 
-	result1, err1 := ExecuteCleanup(options1)
+	result1, err1 := repo.ExecuteCleanup(t.Context(), options1)
 
 	// verbose有効での実行（ログ出力をキャプチャ）
 	var buf bytes.Buffer
@@ -184,7 +181,7 @@ func TestVerboseWithNormalMode(t *testing.T) {
 		Yes:     true,
 	}
 
-	result2, err2 := ExecuteCleanup(options2)
+	result2, err2 := repo.ExecuteCleanup(t.Context(), options2)
 
 	// 結果が同じであることを確認（ログ出力以外）
 	if (err1 == nil) != (err2 == nil) {
@@ -2,64 +2,112 @@ package git
 
 import (
 	"bytes"
+	"context"
 	"fmt"
+	"io"
+	"os"
 	"os/exec"
 	"strings"
+	"time"
 )
 
+// waitDelay はキャンセル後に出力パイプが閉じられるのを待つ最大時間です
+const waitDelay = 2 * time.Second
+
 // CommandResult はGitコマンドの実行結果を表します
 type CommandResult struct {
 	Output string
 	Error  string
 }
 
-// ExecuteCommand はGitコマンドを実行し、結果を返します
-func ExecuteCommand(args ...string) (*CommandResult, error) {
-	cmd := exec.Command("git", args...)
-	
-	var stdout, stderr bytes.Buffer
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
-	
-	err := cmd.Run()
-	
-	result := &CommandResult{
-		Output: strings.TrimSpace(stdout.String()),
-		Error:  strings.TrimSpace(stderr.String()),
+// Command は実行するGitコマンドを表します
+type Command struct {
+	Args  []string  // gitに渡す引数
+	Dir   string    // 作業ディレクトリ（空の場合はカレントディレクトリ）
+	Env   []string  // 追加の環境変数（"KEY=VALUE"形式）
+	Stdin io.Reader // 標準入力（nilの場合は入力なし）
+}
+
+// Runner はGitコマンドを実行するインターフェースです
+// Repositoryはすべてのgit操作をRunner経由で実行するため、テストでは実際のgitの代わりに
+// 任意の出力を返すRunnerを差し替えられます
+type Runner interface {
+	Run(ctx context.Context, cmd Command) (*CommandResult, error)
+}
+
+// ExecRunner はgitの実行ファイルを起動してコマンドを実行するRunnerです
+type ExecRunner struct{}
+
+// defaultRunner はRepository.Runnerが未設定の場合に使用するRunnerです
+var defaultRunner Runner = ExecRunner{}
+
+// ExecuteCommand はリポジトリのディレクトリでGitコマンドを実行し、結果を返します
+func (r *Repository) ExecuteCommand(ctx context.Context, args ...string) (*CommandResult, error) {
+	return r.run(ctx, nil, args...)
+}
+
+// ExecuteCommandWithInput は入力を伴うGitコマンドを実行し、結果を返します
+func (r *Repository) ExecuteCommandWithInput(ctx context.Context, input string, args ...string) (*CommandResult, error) {
+	return r.run(ctx, strings.NewReader(input), args...)
+}
+
+// run はリポジトリの設定（ディレクトリ・GIT_DIR・環境変数）を反映してGitコマンドを実行します
+func (r *Repository) run(ctx context.Context, stdin io.Reader, args ...string) (*CommandResult, error) {
+	cmd := Command{
+		Args:  args,
+		Dir:   r.Dir,
+		Env:   r.Env,
+		Stdin: stdin,
 	}
-	
-	if err != nil {
-		if exitErr, ok := err.(*exec.ExitError); ok {
-			return result, fmt.Errorf("git command failed with exit code %d: %s", exitErr.ExitCode(), result.Error)
-		}
-		return result, fmt.Errorf("failed to execute git command: %w", err)
+	if r.GitDir != "" {
+		cmd.Env = append(append([]string(nil), r.Env...), "GIT_DIR="+r.GitDir)
 	}
-	
-	return result, nil
+
+	runner := r.Runner
+	if runner == nil {
+		runner = defaultRunner
+	}
+	return runner.Run(ctx, cmd)
 }
 
-// ExecuteCommandWithInput は入力を伴うGitコマンドを実行し、結果を返します
-func ExecuteCommandWithInput(input string, args ...string) (*CommandResult, error) {
-	cmd := exec.Command("git", args...)
-	cmd.Stdin = strings.NewReader(input)
-	
+// Run はgitを実行し、標準出力と標準エラー出力を返します
+// ctxがキャンセルされるとgitを終了させ、子プロセス（ssh、credential helperなど）が出力パイプを保持していても
+// waitDelay後には戻ります
+// gitはgitcと同じフォアグラウンドのプロセスグループで実行します。別のプロセスグループにすると、
+// sshのパスフレーズやcredentialの入力で /dev/tty を読む子プロセスがSIGTTINで停止してしまうためです
+// （Ctrl-Cはフォアグラウンドのプロセスグループ全体に送られるため、子プロセスも同時に終了します）
+func (ExecRunner) Run(ctx context.Context, c Command) (*CommandResult, error) {
+	cmd := exec.CommandContext(ctx, "git", c.Args...)
+	cmd.Dir = c.Dir
+	cmd.WaitDelay = waitDelay
+	if c.Stdin != nil {
+		cmd.Stdin = c.Stdin
+	}
+	if len(c.Env) > 0 {
+		cmd.Env = append(os.Environ(), c.Env...)
+	}
+
 	var stdout, stderr bytes.Buffer
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
-	
+
 	err := cmd.Run()
-	
+
 	result := &CommandResult{
 		Output: strings.TrimSpace(stdout.String()),
 		Error:  strings.TrimSpace(stderr.String()),
 	}
-	
+
 	if err != nil {
+		// キャンセル・タイムアウトによる終了は終了コードではなくctxのエラーとして返す
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return result, fmt.Errorf("git %s: %w", c.Args[0], ctxErr)
+		}
 		if exitErr, ok := err.(*exec.ExitError); ok {
 			return result, fmt.Errorf("git command failed with exit code %d: %s", exitErr.ExitCode(), result.Error)
 		}
 		return result, fmt.Errorf("failed to execute git command: %w", err)
 	}
-	
+
 	return result, nil
-}
\ No newline at end of file
+}
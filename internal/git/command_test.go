@@ -0,0 +1,73 @@
+package git
+
+import (
+	"context"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestExecuteCommandCanceled(t *testing.T) {
+	t.Parallel()
+
+	dir, cleanup := createTestGitRepo(t)
+	defer cleanup()
+
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+
+	_, err := NewRepository(dir).ExecuteCommand(ctx, "status")
+	if err == nil {
+		t.Fatal("ExecuteCommand() expected error for canceled context")
+	}
+	if !IsCanceled(err) {
+		t.Errorf("ExecuteCommand() error = %v, want canceled error", err)
+	}
+}
+
+func TestExecuteCommandWithTimeout(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("シェルエイリアスを使用するためWindowsではスキップ")
+	}
+	t.Parallel()
+
+	dir, cleanup := createTestGitRepo(t)
+	defer cleanup()
+
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr bool
+	}{
+		{
+			name:    "タイムアウト前に完了する",
+			args:    []string{"status"},
+			wantErr: false,
+		},
+		{
+			name: "タイムアウトした場合は子プロセスが残っていても戻る",
+			// エイリアス経由でsleepを子プロセスとして起動し、出力パイプを保持させる
+			args:    []string{"-c", "alias.hang=!sleep 30", "hang"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start := time.Now()
+			_, err := NewRepository(dir).ExecuteCommandWithTimeout(t.Context(), 200*time.Millisecond, tt.args...)
+			elapsed := time.Since(start)
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ExecuteCommandWithTimeout() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr && !IsCanceled(err) {
+				t.Errorf("ExecuteCommandWithTimeout() error = %v, want deadline exceeded", err)
+			}
+			// 子プロセスが出力パイプを保持していても、出力パイプの待機(waitDelay)後には戻る
+			if limit := 200*time.Millisecond + waitDelay + time.Second; elapsed >= limit {
+				t.Errorf("ExecuteCommandWithTimeout() took %v, want less than %v", elapsed, limit)
+			}
+		})
+	}
+}
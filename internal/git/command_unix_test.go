@@ -0,0 +1,34 @@
+//go:build unix
+
+package git
+
+import (
+	"os/exec"
+	"strconv"
+	"syscall"
+	"testing"
+)
+
+func TestExecuteCommandForegroundProcessGroup(t *testing.T) {
+	if _, err := exec.LookPath("ps"); err != nil {
+		t.Skip("ps is not available")
+	}
+	t.Parallel()
+
+	dir, cleanup := createTestGitRepo(t)
+	defer cleanup()
+
+	// /dev/tty を読む子プロセス（sshのパスフレーズ入力など）がSIGTTINで停止しないよう、
+	// gitとその子プロセスはgitcと同じプロセスグループで実行されること
+	result, err := NewRepository(dir).ExecuteCommand(t.Context(), "-c", "alias.pgid=!ps -o pgid= -p $$", "pgid")
+	if err != nil {
+		t.Fatalf("ExecuteCommand() error = %v", err)
+	}
+	pgid, err := strconv.Atoi(result.Output)
+	if err != nil {
+		t.Fatalf("unexpected ps output: %q", result.Output)
+	}
+	if want := syscall.Getpgrp(); pgid != want {
+		t.Errorf("process group = %d, want %d", pgid, want)
+	}
+}
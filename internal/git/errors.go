@@ -1,6 +1,7 @@
 package git
 
 import (
+	"context"
 	"errors"
 	"fmt"
 )
@@ -13,6 +14,11 @@ var (
 	ErrMergeConflict        = errors.New("merge conflict detected")
 	ErrBranchNotFound       = errors.New("branch not found")
 	ErrCannotDeleteCurrent  = errors.New("cannot delete current branch")
+	ErrStalePlan            = errors.New("plan is out of date")
+	ErrOperationInProgress  = errors.New("another git operation is in progress")
+	ErrDirtyWorktree        = errors.New("working tree has uncommitted changes")
+	ErrDetachedHead         = errors.New("HEAD is detached")
+	ErrDiverged             = errors.New("branch has diverged from its upstream")
 )
 
 // GitError はGit固有のエラーとコンテキストを表します
@@ -77,4 +83,34 @@ func IsRemoteAccessFailed(err error) bool {
 // IsMergeConflict はエラーがマージコンフリクトを示しているか確認します
 func IsMergeConflict(err error) bool {
 	return errors.Is(err, ErrMergeConflict)
-}
\ No newline at end of file
+}
+
+// IsStalePlan はエラーが実行計画の作成後にリポジトリが変更されたことを示しているか確認します
+func IsStalePlan(err error) bool {
+	return errors.Is(err, ErrStalePlan)
+}
+
+// IsOperationInProgress はエラーがマージやリベースなどの操作が進行中であることを示しているか確認します
+func IsOperationInProgress(err error) bool {
+	return errors.Is(err, ErrOperationInProgress)
+}
+
+// IsDirtyWorktree はエラーが作業ツリーに未コミットの変更があることを示しているか確認します
+func IsDirtyWorktree(err error) bool {
+	return errors.Is(err, ErrDirtyWorktree)
+}
+
+// IsDetachedHead はエラーがdetached HEADでブランチをチェックアウトしていないことを示しているか確認します
+func IsDetachedHead(err error) bool {
+	return errors.Is(err, ErrDetachedHead)
+}
+
+// IsDiverged はエラーがブランチが上流ブランチと分岐していてfast-forwardできないことを示しているか確認します
+func IsDiverged(err error) bool {
+	return errors.Is(err, ErrDiverged)
+}
+
+// IsCanceled はエラーがキャンセルまたはタイムアウトによる中断を示しているか確認します
+func IsCanceled(err error) bool {
+	return errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+}
@@ -0,0 +1,94 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeCall は fakeRunner が受け付けるGitコマンドの呼び出しと、その応答を表します
+type fakeCall struct {
+	args     []string
+	output   string
+	stderr   string
+	exitCode int
+}
+
+// returns は呼び出しが成功し、指定した出力を返すよう設定します
+func (c *fakeCall) returns(output string) *fakeCall {
+	c.output = output
+	return c
+}
+
+// fails は呼び出しが指定した終了コードと標準エラー出力で失敗するよう設定します
+func (c *fakeCall) fails(exitCode int, stderr string) *fakeCall {
+	c.exitCode = exitCode
+	c.stderr = stderr
+	return c
+}
+
+// fakeRunner は期待した順序でGitコマンドが呼び出されることを確認し、用意した応答を返すRunnerです
+// 期待していない呼び出しや、呼び出されなかった期待はテストの失敗として報告されます
+type fakeRunner struct {
+	t     *testing.T
+	mu    sync.Mutex
+	calls []*fakeCall
+	next  int
+}
+
+// newFakeRunner はfakeRunnerを作成し、テスト終了時にすべての期待が呼び出されたか検証します
+func newFakeRunner(t *testing.T) *fakeRunner {
+	t.Helper()
+
+	f := &fakeRunner{t: t}
+	t.Cleanup(func() {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		for _, call := range f.calls[f.next:] {
+			t.Errorf("expected git command was not called: git %s", strings.Join(call.args, " "))
+		}
+	})
+	return f
+}
+
+// expect は次に呼び出されるGitコマンドを追加します
+func (f *fakeRunner) expect(args ...string) *fakeCall {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	call := &fakeCall{args: args}
+	f.calls = append(f.calls, call)
+	return call
+}
+
+// repository はfakeRunnerを使用するRepositoryを返します
+func (f *fakeRunner) repository() *Repository {
+	return &Repository{Dir: f.t.TempDir(), Runner: f}
+}
+
+// Run はRunnerインターフェースを実装します
+func (f *fakeRunner) Run(ctx context.Context, cmd Command) (*CommandResult, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	got := strings.Join(cmd.Args, " ")
+	if f.next >= len(f.calls) {
+		f.t.Errorf("unexpected git command: git %s", got)
+		return &CommandResult{}, fmt.Errorf("unexpected git command: git %s", got)
+	}
+
+	call := f.calls[f.next]
+	if want := strings.Join(call.args, " "); got != want {
+		f.t.Errorf("git command = git %s, want git %s", got, want)
+		return &CommandResult{}, fmt.Errorf("unexpected git command: git %s", got)
+	}
+	f.next++
+
+	result := &CommandResult{Output: call.output, Error: call.stderr}
+	if call.exitCode != 0 {
+		return result, fmt.Errorf("git command failed with exit code %d: %s", call.exitCode, call.stderr)
+	}
+	return result, nil
+}
@@ -0,0 +1,93 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"slices"
+)
+
+// forkBaseRemote はフォーク運用でフォーク元を指すリモートの慣習的な名前です
+const forkBaseRemote = "upstream"
+
+// RemoteRoles はリモートの役割を表します
+// フォーク運用ではマージ済みの判定をフォーク元（upstream）で行い、自分のブランチはフォーク（origin）にプッシュします
+type RemoteRoles struct {
+	Base string `json:"base"` // マージ済みの判定に使用するリモート（フォーク元）
+	Push string `json:"push"` // 自分のブランチをプッシュするリモート（フォーク）
+}
+
+// Fork はベースとプッシュ先が異なるリモート（フォーク運用）かどうかを返します
+func (r *RemoteRoles) Fork() bool {
+	return r.Base != "" && r.Push != "" && r.Base != r.Push
+}
+
+// ResolveRemotes はオプションの指定と設定されているリモートからリモートの役割を決定します
+// ベースの指定がない場合、upstream リモートがあればフォーク運用とみなしてベースにし、なければ主要なリモートを使用します
+// プッシュ先の指定がない場合、ベース以外に origin があれば origin、なければベースと同じリモートを使用します
+func (r *Repository) ResolveRemotes(ctx context.Context, base, push string) (*RemoteRoles, error) {
+	remotes, err := r.ListRemotes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range []string{base, push} {
+		if name != "" && !slices.Contains(remotes, name) {
+			return nil, NewGitError("check-remote", fmt.Errorf("remote %q does not exist", name))
+		}
+	}
+
+	roles := &RemoteRoles{Base: base, Push: push}
+	if roles.Base == "" {
+		if slices.Contains(remotes, forkBaseRemote) && push != forkBaseRemote {
+			roles.Base = forkBaseRemote
+		} else if roles.Base, err = r.PrimaryRemote(ctx); err != nil {
+			return nil, err
+		}
+	}
+	if roles.Push == "" {
+		roles.Push = roles.Base
+		if roles.Base != "origin" && slices.Contains(remotes, "origin") {
+			roles.Push = "origin"
+		}
+	}
+	return roles, nil
+}
+
+// FastForwardBranch はブランチをtarget（例: upstream/main）まで早送りします
+// ブランチがいずれかのワークツリーでチェックアウトされている場合はそのワークツリーで git merge --ff-only を実行し、
+// それ以外の場合はチェックアウトせずに更新します。fast-forwardできない場合は失敗します
+func (r *Repository) FastForwardBranch(ctx context.Context, branch, target string) error {
+	worktrees, err := r.ListWorktrees(ctx)
+	if err != nil {
+		return err
+	}
+	for _, wt := range worktrees {
+		if wt.Branch == branch {
+			if _, err := r.worktree(wt.Path).ExecuteCommand(ctx, "merge", "--ff-only", target); err != nil {
+				return NewGitError("fast-forward", err).WithMessage(fmt.Sprintf("failed to fast-forward %s to %s", branch, target))
+			}
+			return nil
+		}
+	}
+
+	if _, err := r.ExecuteCommand(ctx, "fetch", ".", target+":refs/heads/"+branch); err != nil {
+		return NewGitError("fast-forward", err).WithMessage(fmt.Sprintf("failed to fast-forward %s to %s", branch, target))
+	}
+	return nil
+}
+
+// PushBranch はローカルブランチを同名のリモートブランチにプッシュします
+func (r *Repository) PushBranch(ctx context.Context, remote, branch string) error {
+	ref := "refs/heads/" + branch
+	if _, err := r.ExecuteCommand(ctx, "push", remote, ref+":"+ref); err != nil {
+		return NewGitError("push", err).WithMessage(fmt.Sprintf("failed to push %s to %s", branch, remote))
+	}
+	return nil
+}
+
+// syncFork はフォークのデフォルトブランチをフォーク元から早送りし、フォークにプッシュします
+func (r *Repository) syncFork(ctx context.Context, plan *CleanupPlan) error {
+	if err := r.FastForwardBranch(ctx, plan.DefaultBranch, plan.MergeTarget); err != nil {
+		return err
+	}
+	return r.PushBranch(ctx, plan.Remotes.Push, plan.DefaultBranch)
+}
@@ -0,0 +1,153 @@
+package git
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestResolveRemotes(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		remotes  []string
+		base     string
+		push     string
+		want     RemoteRoles
+		wantFork bool
+		wantErr  bool
+	}{
+		{
+			name:    "originのみ",
+			remotes: []string{"origin"},
+			want:    RemoteRoles{Base: "origin", Push: "origin"},
+		},
+		{
+			name:     "upstreamがある場合はフォーク運用とみなす",
+			remotes:  []string{"origin", "upstream"},
+			want:     RemoteRoles{Base: "upstream", Push: "origin"},
+			wantFork: true,
+		},
+		{
+			name:    "明示的に指定したリモートを優先",
+			remotes: []string{"origin", "upstream"},
+			base:    "origin",
+			want:    RemoteRoles{Base: "origin", Push: "origin"},
+		},
+		{
+			name:     "origin以外の名前のフォーク",
+			remotes:  []string{"fork", "upstream"},
+			push:     "fork",
+			want:     RemoteRoles{Base: "upstream", Push: "fork"},
+			wantFork: true,
+		},
+		{
+			name:    "リモートがない場合",
+			remotes: nil,
+			want:    RemoteRoles{},
+		},
+		{
+			name:    "存在しないリモートを指定した場合はエラー",
+			remotes: []string{"origin"},
+			base:    "upstream",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			dir, cleanup := createTestGitRepo(t)
+			defer cleanup()
+			for _, remote := range tt.remotes {
+				runGit(t, dir, "remote", "add", remote, "https://example.com/"+remote+".git")
+			}
+
+			got, err := NewRepository(dir).ResolveRemotes(t.Context(), tt.base, tt.push)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ResolveRemotes() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if *got != tt.want {
+				t.Errorf("ResolveRemotes() = %+v, want %+v", *got, tt.want)
+			}
+			if got.Fork() != tt.wantFork {
+				t.Errorf("Fork() = %v, want %v", got.Fork(), tt.wantFork)
+			}
+		})
+	}
+}
+
+func TestCleanup_Fork(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		syncFork bool
+	}{
+		{name: "フォーク元でマージされたブランチを削除"},
+		{name: "--sync-forkでフォークのデフォルトブランチを更新してプッシュ", syncFork: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			dir, cleanup := createTestGitRepo(t)
+			defer cleanup()
+
+			// フォーク元（upstream）とフォーク（origin）
+			root := t.TempDir()
+			upstream := filepath.Join(root, "upstream.git")
+			fork := filepath.Join(root, "fork.git")
+			for name, path := range map[string]string{"upstream": upstream, "origin": fork} {
+				runGit(t, dir, "init", "--bare", "-b", "main", path)
+				runGit(t, dir, "remote", "add", name, path)
+				runGit(t, dir, "push", name, "main")
+				runGit(t, dir, "remote", "set-head", name, "main")
+			}
+			runGit(t, dir, "branch", "--set-upstream-to=origin/main", "main")
+
+			// featureをフォークにプッシュし、フォーク元でマージされた状態にする
+			runGit(t, dir, "checkout", "-b", "feature")
+			runGit(t, dir, "commit", "--allow-empty", "-m", "feature work")
+			runGit(t, dir, "push", "origin", "feature")
+			runGit(t, dir, "push", "upstream", "feature:main")
+			runGit(t, dir, "checkout", "main")
+			merged := runGit(t, dir, "rev-parse", "feature")
+			original := runGit(t, dir, "rev-parse", "main")
+
+			result, err := NewRepository(dir).ExecuteCleanup(t.Context(), CleanupOptions{NoPull: true, SyncFork: tt.syncFork})
+			if err != nil {
+				t.Fatalf("ExecuteCleanup() error = %v", err)
+			}
+			if len(result.Errors) != 0 {
+				t.Errorf("Errors = %v, want none", result.Errors)
+			}
+			if result.MergeTarget != "upstream/main" {
+				t.Errorf("MergeTarget = %s, want upstream/main", result.MergeTarget)
+			}
+			if len(result.DeletedBranches) != 1 || result.DeletedBranches[0] != "feature" {
+				t.Errorf("DeletedBranches = %v, want [feature]", result.DeletedBranches)
+			}
+
+			wantMain := original
+			wantForkSynced := ""
+			if tt.syncFork {
+				wantMain, wantForkSynced = merged, "origin"
+			}
+			if result.ForkSynced != wantForkSynced {
+				t.Errorf("ForkSynced = %q, want %q", result.ForkSynced, wantForkSynced)
+			}
+			if got := runGit(t, dir, "rev-parse", "main"); got != wantMain {
+				t.Errorf("main = %s, want %s", got, wantMain)
+			}
+			if got := runGit(t, fork, "rev-parse", "main"); got != wantMain {
+				t.Errorf("fork main = %s, want %s", got, wantMain)
+			}
+		})
+	}
+}
@@ -16,15 +16,15 @@ func TestIntegrationGitOperations(t *testing.T) {
 	t.Run("実際のGitリポジトリでの操作", func(t *testing.T) {
 		dir, cleanup := createTestGitRepo(t)
 		defer cleanup()
-		defer changeDir(t, dir)()
+		repo := NewRepository(dir)
 		
 		// リポジトリ検証
-		if err := IsGitRepository(dir); err != nil {
+		if err := IsGitRepository(t.Context(), dir); err != nil {
 			t.Errorf("IsGitRepository() failed: %v", err)
 		}
 		
 		// 現在のブランチ取得
-		branch, err := GetCurrentBranch()
+		branch, err := repo.GetCurrentBranch(t.Context())
 		if err != nil {
 			t.Fatalf("GetCurrentBranch() failed: %v", err)
 		}
@@ -35,7 +35,7 @@ func TestIntegrationGitOperations(t *testing.T) {
 		}
 		
 		// ローカルブランチ一覧
-		branches, err := ListLocalBranches()
+		branches, err := repo.ListLocalBranches(t.Context())
 		if err != nil {
 			t.Fatalf("ListLocalBranches() failed: %v", err)
 		}
@@ -45,13 +45,13 @@ func TestIntegrationGitOperations(t *testing.T) {
 		}
 		
 		// 新しいブランチを作成してチェックアウト
-		_, err = ExecuteCommand("checkout", "-b", "test-branch")
+		_, err = repo.ExecuteCommand(t.Context(), "checkout", "-b", "test-branch")
 		if err != nil {
 			t.Fatalf("Failed to create test branch: %v", err)
 		}
 		
 		// 新しいブランチに切り替わったことを確認
-		currentBranch, err := GetCurrentBranch()
+		currentBranch, err := repo.GetCurrentBranch(t.Context())
 		if err != nil {
 			t.Fatalf("GetCurrentBranch() after checkout failed: %v", err)
 		}
@@ -61,12 +61,12 @@ func TestIntegrationGitOperations(t *testing.T) {
 		}
 		
 		// 元のブランチに戻る
-		if err := CheckoutBranch(branch); err != nil {
+		if err := repo.CheckoutBranch(t.Context(), branch); err != nil {
 			t.Fatalf("CheckoutBranch() failed: %v", err)
 		}
 		
 		// テストブランチを削除
-		if err := DeleteBranch("test-branch", false); err != nil {
+		if err := repo.DeleteBranch(t.Context(), "test-branch", false); err != nil {
 			t.Errorf("DeleteBranch() failed: %v", err)
 		}
 	})
@@ -0,0 +1,98 @@
+package git
+
+import (
+	"context"
+	"strings"
+)
+
+// MergeStatus はブランチのマージ状態を表します
+type MergeStatus string
+
+const (
+	MergeStatusMerged       MergeStatus = "merged"        // 通常のマージ済み（git branch -d で削除可能）
+	MergeStatusSquashMerged MergeStatus = "squash-merged" // スカッシュマージ済み
+	MergeStatusRebaseMerged MergeStatus = "rebase-merged" // リベースマージ済み（全コミットがパッチ等価）
+	MergeStatusUnmerged     MergeStatus = "unmerged"      // 未マージ
+)
+
+// DetectMergeStatus はbranchがbaseにどのような形でマージされているかを判定します
+// git branch --merged で検出できないスカッシュマージ・リベースマージも判定します
+func (r *Repository) DetectMergeStatus(ctx context.Context, base, branch string) (MergeStatus, error) {
+	// 通常のマージ（branchの先端がbaseから到達可能）
+	if _, err := r.ExecuteCommand(ctx, "merge-base", "--is-ancestor", branch, base); err == nil {
+		return MergeStatusMerged, nil
+	}
+
+	rebased, err := r.IsRebaseMerged(ctx, base, branch)
+	if err != nil {
+		return MergeStatusUnmerged, err
+	}
+	if rebased {
+		return MergeStatusRebaseMerged, nil
+	}
+
+	squashed, err := r.IsSquashMerged(ctx, base, branch)
+	if err != nil {
+		return MergeStatusUnmerged, err
+	}
+	if squashed {
+		return MergeStatusSquashMerged, nil
+	}
+
+	return MergeStatusUnmerged, nil
+}
+
+// IsRebaseMerged はbranch固有のすべてのコミットと等価なパッチがbaseに存在するかを確認します
+func (r *Repository) IsRebaseMerged(ctx context.Context, base, branch string) (bool, error) {
+	result, err := r.ExecuteCommand(ctx, "cherry", base, branch)
+	if err != nil {
+		return false, NewGitError("cherry", err).WithPath(branch)
+	}
+
+	return allPatchesApplied(result.Output), nil
+}
+
+// IsSquashMerged はbranchの変更全体をまとめた1コミットと等価なパッチがbaseに存在するかを確認します
+// マージベースを親としてbranchのツリーを持つ仮のコミットを作成し、git cherry で比較します
+func (r *Repository) IsSquashMerged(ctx context.Context, base, branch string) (bool, error) {
+	mergeBase, err := r.ExecuteCommand(ctx, "merge-base", base, branch)
+	if err != nil {
+		// 共通の祖先がない場合はスカッシュマージではない
+		return false, nil
+	}
+
+	tree, err := r.ExecuteCommand(ctx, "rev-parse", branch+"^{tree}")
+	if err != nil {
+		return false, NewGitError("rev-parse", err).WithPath(branch)
+	}
+
+	// 仮のコミットはどの参照からも指されないため、後でgcにより回収される
+	squash, err := r.ExecuteCommand(ctx, "-c", "user.name=gitc", "-c", "user.email=gitc@localhost",
+		"commit-tree", tree.Output, "-p", mergeBase.Output, "-m", "gitc squash check")
+	if err != nil {
+		return false, NewGitError("commit-tree", err).WithPath(branch)
+	}
+
+	result, err := r.ExecuteCommand(ctx, "cherry", base, squash.Output)
+	if err != nil {
+		return false, NewGitError("cherry", err).WithPath(branch)
+	}
+
+	return allPatchesApplied(result.Output), nil
+}
+
+// allPatchesApplied はgit cherryの出力がすべて適用済み（"-"）かどうかを返します
+// 比較対象のコミットが1つもない場合はfalseを返します
+func allPatchesApplied(output string) bool {
+	lines := filterEmptyStrings(strings.Split(output, "\n"))
+	if len(lines) == 0 {
+		return false
+	}
+
+	for _, line := range lines {
+		if !strings.HasPrefix(line, "-") {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,90 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDetectMergeStatus(t *testing.T) {
+	t.Parallel()
+
+	dir, cleanup := createTestGitRepo(t)
+	defer cleanup()
+	repo := NewRepository(dir)
+
+	commitFile := func(name, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+		runGit(t, dir, "add", name)
+		runGit(t, dir, "commit", "-m", "update "+name)
+	}
+
+	// 通常のマージ
+	runGit(t, dir, "branch", "merged")
+
+	// スカッシュマージ（複数コミットを1コミットにまとめてmainに取り込む）
+	runGit(t, dir, "checkout", "-b", "squashed")
+	commitFile("squash1.txt", "one")
+	commitFile("squash2.txt", "two")
+	runGit(t, dir, "checkout", "main")
+	runGit(t, dir, "merge", "--squash", "squashed")
+	runGit(t, dir, "commit", "-m", "Squash merge")
+
+	// リベースマージ（同じ変更を別コミットとしてmainに取り込む）
+	runGit(t, dir, "checkout", "-b", "rebased")
+	commitFile("rebase1.txt", "one")
+	commitFile("rebase2.txt", "two")
+	runGit(t, dir, "checkout", "main")
+	runGit(t, dir, "commit", "--allow-empty", "-m", "diverge")
+	runGit(t, dir, "cherry-pick", "main..rebased")
+
+	// 未マージ
+	runGit(t, dir, "checkout", "-b", "unmerged")
+	commitFile("wip.txt", "wip")
+	runGit(t, dir, "checkout", "main")
+
+	tests := []struct {
+		branch string
+		want   MergeStatus
+	}{
+		{branch: "merged", want: MergeStatusMerged},
+		{branch: "squashed", want: MergeStatusSquashMerged},
+		{branch: "rebased", want: MergeStatusRebaseMerged},
+		{branch: "unmerged", want: MergeStatusUnmerged},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.branch, func(t *testing.T) {
+			got, err := repo.DetectMergeStatus(t.Context(), "main", tt.branch)
+			if err != nil {
+				t.Fatalf("DetectMergeStatus() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("DetectMergeStatus() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("スカッシュマージされたブランチを削除する", func(t *testing.T) {
+		result, err := repo.ExecuteCleanup(t.Context(), CleanupOptions{NoPull: true})
+		if err != nil {
+			t.Fatalf("ExecuteCleanup() error = %v", err)
+		}
+
+		deleted := make(map[string]bool)
+		for _, branch := range result.DeletedBranches {
+			deleted[branch] = true
+		}
+		for _, branch := range []string{"merged", "squashed", "rebased"} {
+			if !deleted[branch] {
+				t.Errorf("%s should be deleted, DeletedBranches = %v", branch, result.DeletedBranches)
+			}
+		}
+		if deleted["unmerged"] {
+			t.Error("unmerged should not be deleted")
+		}
+	})
+}
@@ -0,0 +1,209 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"slices"
+)
+
+// MigrateOptions はデフォルトブランチ名の変更に追従する処理のオプションを表します
+type MigrateOptions struct {
+	Remote  string // 対象のリモート（空の場合は主要なリモート）
+	From    string // 旧デフォルトブランチ（空の場合は自動検出）
+	DryRun  bool   // 実行のシミュレーションのみ
+	Verbose bool   // 詳細ログの表示
+}
+
+// MigrateResult はデフォルトブランチ名の変更に追従した結果を表します
+type MigrateResult struct {
+	Remote          string   // 対象のリモート
+	From            string   // 旧デフォルトブランチ
+	To              string   // 新デフォルトブランチ（リモートの実際のHEAD）
+	UpToDate        bool     // 移行の必要がなかったかどうか
+	Renamed         bool     // ローカルの旧ブランチを新しい名前に変更したかどうか
+	UpstreamSet     bool     // 新ブランチの上流を <remote>/<To> に設定したかどうか
+	HEADUpdated     bool     // refs/remotes/<remote>/HEAD を更新したかどうか
+	Retargeted      []string // 上流を旧ブランチから新ブランチに付け替えたブランチ
+	DeletedTracking bool     // 古いリモート追跡ブランチ（refs/remotes/<remote>/<From>）を削除したかどうか
+	WasDryRun       bool     // ドライランモードだったかどうか
+}
+
+// MigrateDefaultBranch はリモートでデフォルトブランチの名前が変更された場合（master から main など）に、
+// ローカルのブランチ名・上流ブランチ・refs/remotes/<remote>/HEAD を新しい名前に合わせます
+// 途中で失敗した場合は、それまでに完了した処理を含む結果とエラーを返します
+func (r *Repository) MigrateDefaultBranch(ctx context.Context, options MigrateOptions) (*MigrateResult, error) {
+	logVerbose := newVerboseLogger(options.Verbose)
+	result := &MigrateResult{Remote: options.Remote, WasDryRun: options.DryRun}
+
+	if result.Remote == "" {
+		remote, err := r.PrimaryRemote(ctx)
+		if err != nil {
+			return nil, NewGitError("migrate-default", err)
+		}
+		if remote == "" {
+			return nil, NewGitError("migrate-default", fmt.Errorf("no remote configured"))
+		}
+		result.Remote = remote
+	}
+	logVerbose("対象のリモート: %s", result.Remote)
+
+	// 1. リモートの実際のHEADと比較（新しいブランチのリモート追跡ブランチを作成するため先にフェッチする）
+	logVerbose("フェッチ処理を開始 (git fetch %s)", result.Remote)
+	if _, err := r.ExecuteCommand(ctx, "fetch", result.Remote); err != nil {
+		return nil, NewGitError("migrate-default", err).WithMessage(fmt.Sprintf("failed to fetch %s", result.Remote))
+	}
+	status, err := r.CheckRemoteHEAD(ctx, result.Remote)
+	if err != nil {
+		return nil, NewGitError("migrate-default", err)
+	}
+	result.To = status.Actual
+	logVerbose("リモートのデフォルトブランチ: %s (refs/remotes/%s/HEAD: %s)", status.Actual, result.Remote, status.Local)
+
+	// 2. 旧デフォルトブランチの検出
+	result.From = options.From
+	if result.From == "" {
+		result.From, err = r.detectRenamedDefault(ctx, status)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if result.From == "" || result.From == result.To {
+		logVerbose("デフォルトブランチは変更されていません: %s", result.To)
+		result.UpToDate = true
+		return result, nil
+	}
+	logVerbose("デフォルトブランチの変更を検出: %s -> %s", result.From, result.To)
+
+	// 旧ブランチがリモートに残っている場合は、名前の変更ではなく別のブランチへの切り替えの可能性がある
+	remaining, err := r.remoteBranchExists(ctx, result.Remote, result.From)
+	if err != nil {
+		return nil, NewGitError("migrate-default", err)
+	}
+	if remaining && options.From == "" {
+		return nil, NewGitError("migrate-default", fmt.Errorf("%s still exists on %s, so the default branch was switched rather than renamed", result.From, result.Remote)).
+			WithMessage("specify --from to migrate anyway, or run \"gitc doctor --fix-head\" to only update the remote HEAD")
+	}
+
+	oldUpstream := result.Remote + "/" + result.From
+	newUpstream := result.Remote + "/" + result.To
+	branches, err := r.ListBranchInfos(ctx)
+	if err != nil {
+		return nil, NewGitError("migrate-default", err)
+	}
+	names := branchNames(branches)
+
+	// 3. ローカルの旧ブランチの名前を変更（新しい名前のブランチがすでにある場合はそのまま使う）
+	if slices.Contains(names, result.From) && !slices.Contains(names, result.To) {
+		logVerbose("ブランチ名を変更: %s -> %s", result.From, result.To)
+		if !options.DryRun {
+			if _, err := r.ExecuteCommand(ctx, "branch", "-m", result.From, result.To); err != nil {
+				return result, NewGitError("migrate-default", err).WithPath(result.From)
+			}
+		}
+		result.Renamed = true
+	}
+
+	// 4. 新ブランチの上流を付け替え
+	if result.Renamed || slices.Contains(names, result.To) {
+		logVerbose("上流ブランチを設定: %s -> %s", result.To, newUpstream)
+		if !options.DryRun {
+			if err := r.setUpstream(ctx, result.To, newUpstream); err != nil {
+				return result, err
+			}
+		}
+		result.UpstreamSet = true
+	}
+
+	// 5. refs/remotes/<remote>/HEAD を更新
+	if status.Local != result.To {
+		logVerbose("refs/remotes/%s/HEAD を更新: %s", result.Remote, result.To)
+		if !options.DryRun {
+			if err := r.SetRemoteHEAD(ctx, result.Remote, result.To); err != nil {
+				return result, NewGitError("migrate-default", err)
+			}
+		}
+		result.HEADUpdated = true
+	}
+
+	// 6. 旧ブランチを上流にしていた他のブランチを付け替え
+	for _, branch := range branches {
+		if branch.Upstream != oldUpstream || branch.Name == result.From || branch.Name == result.To {
+			continue
+		}
+		logVerbose("上流ブランチを付け替え: %s (%s -> %s)", branch.Name, oldUpstream, newUpstream)
+		if !options.DryRun {
+			if err := r.setUpstream(ctx, branch.Name, newUpstream); err != nil {
+				return result, err
+			}
+		}
+		result.Retargeted = append(result.Retargeted, branch.Name)
+	}
+
+	// 7. リモートから削除された旧ブランチのリモート追跡ブランチを削除
+	if !remaining && r.refExists(ctx, "refs/remotes/"+oldUpstream) {
+		logVerbose("古いリモート追跡ブランチを削除: %s", oldUpstream)
+		if !options.DryRun {
+			if _, err := r.ExecuteCommand(ctx, "update-ref", "-d", "refs/remotes/"+oldUpstream); err != nil {
+				return result, NewGitError("migrate-default", err).WithPath(oldUpstream)
+			}
+		}
+		result.DeletedTracking = true
+	}
+
+	return result, nil
+}
+
+// detectRenamedDefault はリモートのHEADと比較して、名前が変更される前の旧デフォルトブランチを検出します
+// refs/remotes/<remote>/HEAD が古いブランチを指している場合はそのブランチ、
+// すでに更新済みの場合は、新しい名前のローカルブランチがなく、上流がリモートから削除された
+// 一般的なデフォルトブランチ名のローカルブランチを旧デフォルトブランチとみなします
+func (r *Repository) detectRenamedDefault(ctx context.Context, status *RemoteHEADStatus) (string, error) {
+	if status.Stale() {
+		return status.Local, nil
+	}
+
+	branches, err := r.ListBranchInfos(ctx)
+	if err != nil {
+		return "", NewGitError("migrate-default", err)
+	}
+	names := branchNames(branches)
+	if slices.Contains(names, status.Actual) {
+		return "", nil
+	}
+	for _, name := range commonDefaultBranches {
+		for _, branch := range branches {
+			if branch.Name != name || branch.Upstream != status.Remote+"/"+name {
+				continue
+			}
+			if branch.IsUpstreamGone() {
+				return name, nil
+			}
+			// プルーンしていない場合はリモート追跡ブランチが残っているため、リモートに問い合わせる
+			exists, err := r.remoteBranchExists(ctx, status.Remote, name)
+			if err != nil {
+				return "", NewGitError("migrate-default", err)
+			}
+			if !exists {
+				return name, nil
+			}
+		}
+	}
+	return "", nil
+}
+
+// remoteBranchExists はリモートに問い合わせ、指定したブランチが存在するか確認します
+func (r *Repository) remoteBranchExists(ctx context.Context, remote, branch string) (bool, error) {
+	result, err := r.ExecuteCommandWithTimeout(ctx, remoteQueryTimeout, "ls-remote", "--heads", remote, "refs/heads/"+branch)
+	if err != nil {
+		return false, NewGitError("ls-remote", err).WithPath(remote)
+	}
+	return result.Output != "", nil
+}
+
+// setUpstream はブランチの上流ブランチを設定します
+func (r *Repository) setUpstream(ctx context.Context, branch, upstream string) error {
+	if _, err := r.ExecuteCommand(ctx, "branch", "--set-upstream-to="+upstream, branch); err != nil {
+		return NewGitError("set-upstream", err).WithPath(branch)
+	}
+	return nil
+}
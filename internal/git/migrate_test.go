@@ -0,0 +1,149 @@
+package git
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+// setupRenamedDefault はmasterをデフォルトブランチとするリモートを作成し、
+// リモート側でmasterをmainに名前変更した状態のリポジトリを返します
+func setupRenamedDefault(t *testing.T) (dir, remote string) {
+	t.Helper()
+
+	dir, cleanup := createTestGitRepo(t)
+	t.Cleanup(cleanup)
+	runGit(t, dir, "branch", "-m", "main", "master")
+
+	remote = filepath.Join(t.TempDir(), "remote.git")
+	runGit(t, dir, "init", "--bare", "-b", "master", remote)
+	runGit(t, dir, "remote", "add", "origin", remote)
+	runGit(t, dir, "push", "-u", "origin", "master")
+	runGit(t, dir, "remote", "set-head", "origin", "master")
+	runGit(t, dir, "branch", "--track", "feature", "origin/master")
+
+	// ホスティングサービスでのデフォルトブランチ名の変更（HEADも追従する）
+	runGit(t, remote, "branch", "-m", "master", "main")
+	return dir, remote
+}
+
+func TestMigrateDefaultBranch(t *testing.T) {
+	t.Parallel()
+
+	dir, _ := setupRenamedDefault(t)
+	repo := NewRepository(dir)
+
+	result, err := repo.MigrateDefaultBranch(t.Context(), MigrateOptions{})
+	if err != nil {
+		t.Fatalf("MigrateDefaultBranch() error = %v", err)
+	}
+
+	want := MigrateResult{
+		Remote:          "origin",
+		From:            "master",
+		To:              "main",
+		Renamed:         true,
+		UpstreamSet:     true,
+		HEADUpdated:     true,
+		DeletedTracking: true,
+	}
+	if result.Remote != want.Remote || result.From != want.From || result.To != want.To ||
+		result.Renamed != want.Renamed || result.UpstreamSet != want.UpstreamSet ||
+		result.HEADUpdated != want.HEADUpdated || result.DeletedTracking != want.DeletedTracking {
+		t.Errorf("MigrateDefaultBranch() = %+v, want %+v", result, want)
+	}
+	if len(result.Retargeted) != 1 || result.Retargeted[0] != "feature" {
+		t.Errorf("Retargeted = %v, want [feature]", result.Retargeted)
+	}
+
+	checks := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"branch", "--format=%(refname:short)"}, "feature\nmain"},
+		{[]string{"rev-parse", "--abbrev-ref", "main@{upstream}"}, "origin/main"},
+		{[]string{"rev-parse", "--abbrev-ref", "feature@{upstream}"}, "origin/main"},
+		{[]string{"symbolic-ref", "refs/remotes/origin/HEAD"}, "refs/remotes/origin/main"},
+		{[]string{"for-each-ref", "--format=%(refname)", "refs/remotes/origin/master"}, ""},
+	}
+	for _, check := range checks {
+		if got := runGit(t, dir, check.args...); got != check.want {
+			t.Errorf("git %v = %q, want %q", check.args, got, check.want)
+		}
+	}
+
+	// 2回目は何もしない
+	again, err := repo.MigrateDefaultBranch(t.Context(), MigrateOptions{})
+	if err != nil {
+		t.Fatalf("MigrateDefaultBranch() error = %v", err)
+	}
+	if !again.UpToDate {
+		t.Errorf("UpToDate = false, want true: %+v", again)
+	}
+}
+
+func TestMigrateDefaultBranch_Cases(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		setup      func(t *testing.T, dir, remote string)
+		options    MigrateOptions
+		wantErr    bool
+		wantFrom   string
+		wantBranch string // 実行後のローカルブランチ一覧
+	}{
+		{
+			name:       "ドライランでは何も変更しない",
+			setup:      func(t *testing.T, dir, remote string) {},
+			options:    MigrateOptions{DryRun: true},
+			wantFrom:   "master",
+			wantBranch: "feature\nmaster",
+		},
+		{
+			name: "origin/HEADを更新済みでも上流が削除されたブランチから検出",
+			setup: func(t *testing.T, dir, remote string) {
+				runGit(t, dir, "fetch", "--prune", "origin")
+				runGit(t, dir, "remote", "set-head", "origin", "main")
+			},
+			wantFrom:   "master",
+			wantBranch: "feature\nmain",
+		},
+		{
+			name: "旧ブランチがリモートに残っている場合は切り替えとみなして中止",
+			setup: func(t *testing.T, dir, remote string) {
+				runGit(t, remote, "branch", "master", "main")
+			},
+			wantErr:    true,
+			wantBranch: "feature\nmaster",
+		},
+		{
+			name: "--from指定時は旧ブランチがリモートに残っていても移行",
+			setup: func(t *testing.T, dir, remote string) {
+				runGit(t, remote, "branch", "master", "main")
+			},
+			options:    MigrateOptions{From: "master"},
+			wantFrom:   "master",
+			wantBranch: "feature\nmain",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			dir, remote := setupRenamedDefault(t)
+			tt.setup(t, dir, remote)
+
+			result, err := NewRepository(dir).MigrateDefaultBranch(t.Context(), tt.options)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("MigrateDefaultBranch() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && result.From != tt.wantFrom {
+				t.Errorf("From = %q, want %q", result.From, tt.wantFrom)
+			}
+			if got := runGit(t, dir, "branch", "--format=%(refname:short)"); got != tt.wantBranch {
+				t.Errorf("branches = %q, want %q", got, tt.wantBranch)
+			}
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package git
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// regexPatternPrefix は正規表現パターンを示す接頭辞です
+const regexPatternPrefix = "re:"
+
+// BranchPattern はブランチ名にマッチするパターンを表します
+// "re:" で始まる場合は正規表現、それ以外はグロブ（path.Matchの構文）として扱います
+// グロブの "*" は "/" にはマッチしないため、"release/*" は "release/1.0" にのみマッチします
+type BranchPattern struct {
+	raw string         // 指定されたパターン文字列
+	re  *regexp.Regexp // 正規表現パターンの場合のみ設定
+}
+
+// ParseBranchPattern はパターン文字列を解析します
+func ParseBranchPattern(pattern string) (*BranchPattern, error) {
+	if strings.TrimSpace(pattern) == "" {
+		return nil, fmt.Errorf("empty branch pattern")
+	}
+
+	if expr, ok := strings.CutPrefix(pattern, regexPatternPrefix); ok {
+		if expr == "" {
+			return nil, fmt.Errorf("empty regex pattern %q", pattern)
+		}
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regex pattern %q: %w", pattern, err)
+		}
+		return &BranchPattern{raw: pattern, re: re}, nil
+	}
+
+	// 不正なグロブは空文字列とのマッチでも検出される
+	if _, err := path.Match(pattern, ""); err != nil {
+		return nil, fmt.Errorf("invalid glob pattern %q: %w", pattern, err)
+	}
+	return &BranchPattern{raw: pattern}, nil
+}
+
+// ParseBranchPatterns は複数のパターン文字列を解析します
+func ParseBranchPatterns(patterns []string) ([]*BranchPattern, error) {
+	parsed := make([]*BranchPattern, 0, len(patterns))
+	for _, pattern := range patterns {
+		p, err := ParseBranchPattern(pattern)
+		if err != nil {
+			return nil, err
+		}
+		parsed = append(parsed, p)
+	}
+	return parsed, nil
+}
+
+// Match はブランチ名がパターンにマッチするか判定します
+func (p *BranchPattern) Match(branch string) bool {
+	if p.re != nil {
+		return p.re.MatchString(branch)
+	}
+	matched, _ := path.Match(p.raw, branch)
+	return matched
+}
+
+// String は指定されたパターン文字列を返します
+func (p *BranchPattern) String() string {
+	return p.raw
+}
+
+// matchBranchPatterns はブランチ名に最初にマッチしたパターンを返します（マッチしなければnil）
+func matchBranchPatterns(branch string, patterns []*BranchPattern) *BranchPattern {
+	for _, p := range patterns {
+		if p.Match(branch) {
+			return p
+		}
+	}
+	return nil
+}
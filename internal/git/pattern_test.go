@@ -0,0 +1,45 @@
+package git
+
+import (
+	"testing"
+)
+
+func TestBranchPattern_Match(t *testing.T) {
+	tests := []struct {
+		name    string
+		pattern string
+		branch  string
+		want    bool
+	}{
+		{name: "完全一致", pattern: "develop", branch: "develop", want: true},
+		{name: "完全一致しない", pattern: "develop", branch: "develop2", want: false},
+		{name: "グロブ（接頭辞）", pattern: "hotfix-*", branch: "hotfix-123", want: true},
+		{name: "グロブ（階層）", pattern: "release/*", branch: "release/1.0", want: true},
+		{name: "グロブは/を越えない", pattern: "release/*", branch: "release/1.0/rc", want: false},
+		{name: "グロブ（文字クラス）", pattern: "v[0-9]", branch: "v1", want: true},
+		{name: "正規表現", pattern: `re:^feature/JIRA-\d+`, branch: "feature/JIRA-42-login", want: true},
+		{name: "正規表現にマッチしない", pattern: `re:^feature/JIRA-\d+$`, branch: "feature/JIRA-42-login", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := ParseBranchPattern(tt.pattern)
+			if err != nil {
+				t.Fatalf("ParseBranchPattern() error = %v", err)
+			}
+			if got := p.Match(tt.branch); got != tt.want {
+				t.Errorf("Match(%q) = %v, want %v", tt.branch, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseBranchPattern_Invalid(t *testing.T) {
+	for _, pattern := range []string{"", "  ", "release/[", "re:feature/(", "re:"} {
+		t.Run(pattern, func(t *testing.T) {
+			if _, err := ParseBranchPattern(pattern); err == nil {
+				t.Errorf("ParseBranchPattern(%q) expected error", pattern)
+			}
+		})
+	}
+}
@@ -0,0 +1,849 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// CleanupPlanVersion は実行計画のフォーマットバージョンです
+const CleanupPlanVersion = 1
+
+// CleanupPlan はクリーンアップ処理の実行計画を表します
+// JSONにシリアライズしてレビューした後、そのままの内容で適用できます
+type CleanupPlan struct {
+	Version         int              `json:"version"`                    // 計画フォーマットのバージョン
+	CreatedAt       time.Time        `json:"created_at"`                 // 計画の作成日時
+	Repository      *RepositoryInfo  `json:"repository,omitempty"`       // 計画を作成したリポジトリ
+	DefaultBranch   string           `json:"default_branch"`             // デフォルトブランチ
+	DefaultSource   string           `json:"default_source,omitempty"`   // デフォルトブランチを検出した方法
+	MergeTarget     string           `json:"merge_target,omitempty"`     // マージ済みの判定に使用したブランチ（フォーク運用では upstream/main など）
+	Remotes         *RemoteRoles     `json:"remotes,omitempty"`          // リモートの役割（リモートがない場合はnil）
+	SyncFork        bool             `json:"sync_fork,omitempty"`        // 適用時にフォークのデフォルトブランチを同期するかどうか
+	CurrentBranch   string           `json:"current_branch"`             // 計画作成時のブランチ
+	Checkout        *CheckoutAction  `json:"checkout,omitempty"`         // デフォルトブランチへの切り替え（不要ならnil）
+	BareLayout      bool             `json:"bare_layout,omitempty"`      // ベアリポジトリ＋ワークツリー構成かどうか
+	DefaultWorktree string           `json:"default_worktree,omitempty"` // デフォルトブランチをチェックアウトしている別のワークツリー（切り替えを行わない）
+	Fetched         bool             `json:"fetched"`                    // 計画作成前にフェッチ済みかどうか
+	Pull            bool             `json:"pull"`                       // 適用時にプルを行うかどうか
+	PullStrategy    PullStrategy     `json:"pull_strategy,omitempty"`    // プルの方法（空の場合は ff-only）
+	Branches        []BranchDecision `json:"branches"`                   // ブランチごとの判定結果
+	Warnings        []string         `json:"warnings,omitempty"`         // 計画作成中の警告
+}
+
+// CheckoutAction はブランチ切り替えの内容を表します
+type CheckoutAction struct {
+	Dirty      bool   `json:"dirty,omitempty"`       // 計画作成時に未コミットの変更があったかどうか
+	From       string `json:"from,omitempty"`        // 切り替え元のブランチ（detached HEADの場合は空）
+	Detached   bool   `json:"detached,omitempty"`    // 切り替え元がdetached HEADかどうか
+	FromCommit string `json:"from_commit,omitempty"` // detached HEADのコミット（--returnで戻るため）
+	Orphaned   bool   `json:"orphaned,omitempty"`    // detached HEADのコミットがどの参照からも到達できないかどうか
+	To         string `json:"to"`                    // 切り替え先のブランチ
+}
+
+// head は切り替え元のHEADの状態を返します
+func (c *CheckoutAction) head() HeadState {
+	return HeadState{Branch: c.From, Commit: c.FromCommit, Detached: c.Detached}
+}
+
+// BranchAction はブランチに対して行う処理を表します
+type BranchAction string
+
+const (
+	BranchActionDelete      BranchAction = "delete"       // 削除する（git branch -d）
+	BranchActionForceDelete BranchAction = "force-delete" // 強制削除する（git branch -D）
+	BranchActionSkip        BranchAction = "skip"         // 削除しない
+)
+
+// BranchDecision はブランチごとの判定結果とその理由を表します
+type BranchDecision struct {
+	Branch     string       `json:"branch"`              // ブランチ名
+	Action     BranchAction `json:"action"`              // 処理内容
+	Reason     string       `json:"reason"`              // 判定理由
+	SHA        string       `json:"sha"`                 // 計画作成時のブランチ先端のコミット
+	CommitTime time.Time    `json:"commit_time"`         // ブランチ先端のコミット日時
+	Subject    string       `json:"subject,omitempty"`   // ブランチ先端のコミットの件名
+	Locked     bool         `json:"locked,omitempty"`    // 対話モードでも変更できない判定（デフォルトブランチ・保護・除外対象）
+	Protected  bool         `json:"protected,omitempty"` // 保護ブランチかどうか
+
+	MergedInto     string `json:"merged_into,omitempty"`     // マージ済みと判定した対象のブランチ（未マージの場合は空）
+	Worktree       string `json:"worktree,omitempty"`        // ブランチをチェックアウトしている別のワークツリー
+	RemoveWorktree bool   `json:"remove_worktree,omitempty"` // ブランチの削除前にワークツリーを削除するかどうか
+
+	Ahead         int    `json:"ahead"`                    // デフォルトブランチに対する先行コミット数
+	Behind        int    `json:"behind"`                   // デフォルトブランチに対する遅行コミット数
+	Upstream      string `json:"upstream,omitempty"`       // 上流ブランチ
+	UpstreamTrack string `json:"upstream_track,omitempty"` // 上流ブランチとの差分
+}
+
+// PlanCleanup はローカルブランチを変更せずにクリーンアップの実行計画を作成します
+// リモート参照の更新（フェッチ）のみ、判定を正確にするため計画作成前に実行します
+func (r *Repository) PlanCleanup(ctx context.Context, options CleanupOptions) (*CleanupPlan, error) {
+	logVerbose := newVerboseLogger(options.Verbose)
+
+	// オプションのバリデーション
+	if err := options.Validate(); err != nil {
+		return nil, err
+	}
+
+	logVerbose("クリーンアップ処理を開始します")
+	logVerbose("オプション: DryRun=%t, Verbose=%t, Yes=%t, Force=%t, Gone=%t", options.DryRun, options.Verbose, options.Yes, options.Force, options.Gone)
+
+	plan := &CleanupPlan{
+		Version:   CleanupPlanVersion,
+		CreatedAt: time.Now().UTC(),
+	}
+	if strategy := options.pullStrategy(); strategy != PullNone {
+		plan.Pull = true
+		plan.PullStrategy = strategy
+	}
+
+	// 1. Gitリポジトリかどうかの確認
+	logVerbose("Gitリポジトリの確認を開始")
+	info, err := r.DetectRepository(ctx)
+	if err != nil {
+		if IsNotGitRepository(err) {
+			return nil, NewGitError("cleanup", ErrNotGitRepository).WithPath(r.Dir)
+		}
+		return nil, NewGitError("cleanup", err)
+	}
+	logVerbose("対象ディレクトリ: %s", info.TopLevel)
+	logVerbose("Gitディレクトリ: %s (共有: %s)", info.GitDir, info.CommonDir)
+	logVerbose("Gitリポジトリであることを確認")
+	plan.Repository = info
+
+	// マージやリベースの途中で実行すると状況を悪化させるため、何もせずに終了する
+	if err := r.CheckInProgress(ctx); err != nil {
+		return nil, err
+	}
+
+	// ワークツリーの一覧（別のワークツリーでチェックアウトされているブランチは削除できない）
+	worktrees, err := r.ListWorktrees(ctx)
+	if err != nil {
+		logVerbose("ワークツリー一覧の取得エラー: %v", err)
+		plan.Warnings = append(plan.Warnings, fmt.Sprintf("failed to list worktrees: %v", err))
+	}
+
+	// ベアリポジトリ＋ワークツリー構成（repo.git/ と main/, feature-x/ など）の検出
+	// 最初のエントリがメインのワークツリーで、ベアリポジトリの場合は "bare" となる
+	plan.BareLayout = info.Bare || (len(worktrees) > 0 && worktrees[0].Bare)
+	if plan.BareLayout {
+		logVerbose("ベアリポジトリ＋ワークツリー構成を検出")
+	} else if info.TopLevel == "" {
+		return nil, NewGitError("cleanup", fmt.Errorf("must be run in a work tree")).WithPath(info.GitDir)
+	}
+
+	// 2. リモートの役割とデフォルトブランチの検出
+	remotes, err := r.ResolveRemotes(ctx, options.BaseRemote, options.PushRemote)
+	if err != nil {
+		return nil, NewGitError("cleanup", err)
+	}
+	if remotes.Base != "" {
+		plan.Remotes = remotes
+		logVerbose("リモート: ベース=%s, プッシュ先=%s", remotes.Base, remotes.Push)
+	}
+	if options.SyncFork && !remotes.Fork() {
+		return nil, NewGitError("cleanup", fmt.Errorf("--sync-fork requires different base and push remotes (e.g. upstream and origin)"))
+	}
+	plan.SyncFork = options.SyncFork
+
+	logVerbose("デフォルトブランチの検出を開始")
+	var defaultBranch string
+	var detected *DefaultBranch
+	if options.DefaultBranch != "" {
+		// 手動指定されたブランチの存在確認
+		exists, err := r.BranchExists(ctx, options.DefaultBranch)
+		if err != nil {
+			return nil, NewGitError("cleanup", err).WithMessage("failed to check branch existence")
+		}
+		if !exists {
+			return nil, NewGitError("cleanup", fmt.Errorf("specified branch '%s' does not exist", options.DefaultBranch))
+		}
+		defaultBranch = options.DefaultBranch
+		plan.DefaultSource = string(DefaultBranchFromOption)
+		logVerbose("手動指定されたデフォルトブランチ: %s", defaultBranch)
+	} else {
+		detected, err = r.ResolveDefaultBranchFrom(ctx, remotes.Base)
+		if err != nil {
+			return nil, NewGitError("cleanup", err)
+		}
+		defaultBranch = detected.Name
+		plan.DefaultSource = string(detected.Strategy)
+		logVerbose("検出されたデフォルトブランチ: %s (%s)", defaultBranch, detected.Describe())
+	}
+	plan.DefaultBranch = defaultBranch
+
+	// リモートや上流ブランチがない場合、git pull は毎回失敗するだけなのでプルを行わない
+	// （ベアリポジトリ＋ワークツリー構成では上流ブランチがなくても origin の同名のブランチからフェッチする）
+	if plan.Pull {
+		if remotes.Base == "" {
+			logVerbose("リモートがないため、プルを行いません")
+			plan.Pull, plan.PullStrategy = false, ""
+		} else if merge, err := r.getConfigValues(ctx, "branch."+defaultBranch+".merge"); err == nil && len(merge) == 0 && !plan.BareLayout {
+			logVerbose("%s に上流ブランチが設定されていないため、プルを行いません", defaultBranch)
+			plan.Pull, plan.PullStrategy = false, ""
+		}
+	}
+
+	// 3. デフォルトブランチへの切り替え要否
+	logVerbose("現在のブランチ確認を開始")
+	head := &HeadState{}
+	if info.TopLevel != "" {
+		head, err = r.GetHead(ctx)
+		if err != nil {
+			return nil, NewGitError("cleanup", err)
+		}
+		logVerbose("現在のブランチ: %s", head)
+	}
+	plan.CurrentBranch = head.Branch
+
+	// デフォルトブランチが別のワークツリーでチェックアウトされている場合は切り替えられない
+	if !plan.BareLayout && head.Branch != defaultBranch {
+		for _, wt := range worktrees {
+			if wt.Branch == defaultBranch && wt.Path != info.TopLevel {
+				plan.DefaultWorktree = wt.Path
+				break
+			}
+		}
+	}
+
+	if plan.BareLayout {
+		// 各ブランチは専用のワークツリーでチェックアウトするため、切り替えは行わない
+		logVerbose("ベアリポジトリ＋ワークツリー構成のため、ブランチの切り替えは行いません")
+	} else if plan.DefaultWorktree != "" {
+		logVerbose("デフォルトブランチは別のワークツリーでチェックアウトされているため、ブランチの切り替えは行いません: %s", plan.DefaultWorktree)
+	} else if head.Detached || head.Branch != defaultBranch {
+		logVerbose("デフォルトブランチへの切り替えを計画: %s -> %s", head, defaultBranch)
+		plan.Checkout = &CheckoutAction{From: head.Branch, To: defaultBranch}
+		if head.Detached {
+			plan.Checkout.Detached = true
+			plan.Checkout.FromCommit = head.Commit
+			r.checkOrphaned(ctx, plan, logVerbose)
+		}
+
+		// 未コミットの変更がある場合の扱いを確認（abortの場合はここで終了する）
+		dirty, err := r.checkDirty(ctx, options)
+		if err != nil {
+			return nil, err
+		}
+		plan.Checkout.Dirty = dirty
+	} else {
+		logVerbose("すでにデフォルトブランチにいます")
+	}
+
+	// 4. フェッチ処理（必須・ドライランでも実行）
+	logVerbose("フェッチ処理を開始 (git fetch --all --prune)")
+	if err := r.Fetch(ctx); err != nil {
+		// 中断された場合は計画を作成せずに終了する
+		if IsCanceled(err) {
+			return nil, NewGitError("cleanup", err).WithMessage("fetch interrupted")
+		}
+		// フェッチ失敗は警告として扱い、処理を継続
+		logVerbose("フェッチエラー: %v", err)
+		plan.Warnings = append(plan.Warnings, fmt.Sprintf("fetch failed: %v", err))
+	} else {
+		logVerbose("フェッチ完了")
+		plan.Fetched = true
+	}
+
+	// リモートのデフォルトブランチが変更されている場合は警告する（自動では変更しない）
+	if plan.Fetched && detected != nil && detected.Strategy == DefaultBranchFromRemoteHEAD {
+		if status, err := r.CheckRemoteHEAD(ctx, detected.Remote); err != nil {
+			logVerbose("リモートHEADの確認エラー: %v", err)
+		} else if status.Stale() {
+			logVerbose("refs/remotes/%s/HEAD (%s) がリモートのHEAD (%s) と異なります", status.Remote, status.Local, status.Actual)
+			plan.Warnings = append(plan.Warnings, staleRemoteHEADWarning(status))
+		}
+	}
+
+	// フォーク運用ではフォーク元のデフォルトブランチ（upstream/main など）に対してマージ済みかを判定する
+	// プルする場合は、プル後のデフォルトブランチに含まれるブランチをマージ済みとする
+	// プルは判定の後に行われローカルのデフォルトブランチはまだ古いため、フェッチ済みのリモート追跡ブランチ（origin/main など）に対しても判定する
+	mergeTarget := defaultBranch
+	if remotes.Fork() || (plan.Pull && plan.Fetched && remotes.Base != "") {
+		if ref := remotes.Base + "/" + defaultBranch; r.refExists(ctx, "refs/remotes/"+ref) {
+			mergeTarget = ref
+		} else if remotes.Fork() {
+			plan.Warnings = append(plan.Warnings, fmt.Sprintf("refs/remotes/%s not found; merged state is judged against %s", ref, defaultBranch))
+		}
+	}
+	plan.MergeTarget = mergeTarget
+	logVerbose("マージ済みの判定対象: %s", mergeTarget)
+
+	// 5. ローカルブランチの一覧取得
+	logVerbose("ローカルブランチ一覧を取得")
+	branches, err := r.ListBranchInfos(ctx)
+	if err != nil {
+		return nil, NewGitError("cleanup", err)
+	}
+	logVerbose("検出されたブランチ: %v", branchNames(branches))
+
+	mergedBranches, err := r.ListMergedBranches(ctx, mergeTarget)
+	if err != nil {
+		return nil, NewGitError("cleanup", err)
+	}
+	merged := make(map[string]bool, len(mergedBranches))
+	for _, branch := range mergedBranches {
+		merged[branch] = true
+	}
+
+	// プルしてもローカルのデフォルトブランチのコミット（未プッシュのものを含む）は残るため、
+	// --pull=none の場合と同様にローカルのデフォルトブランチにマージ済みのブランチもマージ済みとする
+	localMerged := make(map[string]bool)
+	if mergeTarget != defaultBranch && !remotes.Fork() {
+		localBranches, err := r.ListMergedBranches(ctx, defaultBranch)
+		if err != nil {
+			return nil, NewGitError("cleanup", err)
+		}
+		for _, branch := range localBranches {
+			localMerged[branch] = true
+		}
+	}
+
+	// 6. ブランチごとの判定
+	logVerbose("ブランチの判定を開始")
+	protectedPatterns, err := r.loadProtectedPatterns(ctx, options)
+	if err != nil {
+		return nil, NewGitError("cleanup", err)
+	}
+	logVerbose("保護ブランチのパターン: %v", protectedPatterns)
+
+	filter, err := newBranchFilter(options, protectedPatterns)
+	if err != nil {
+		return nil, NewGitError("cleanup", err)
+	}
+
+	// ベアリポジトリ＋ワークツリー構成やデフォルトブランチに切り替えられない場合は、
+	// 実行中のワークツリーのブランチも削除できないため対象に含める
+	currentWorktree := info.TopLevel
+	if plan.BareLayout || plan.DefaultWorktree != "" {
+		currentWorktree = ""
+	}
+	checkedOut := worktreeBranches(worktrees, currentWorktree)
+	for _, branch := range branches {
+		if err := ctx.Err(); err != nil {
+			return nil, NewGitError("cleanup", err)
+		}
+
+		target := mergeTarget
+		if localMerged[branch.Name] {
+			target = defaultBranch
+		}
+		status := MergeStatusMerged
+		if !merged[branch.Name] && !localMerged[branch.Name] && branch.Name != defaultBranch {
+			status, err = r.DetectMergeStatus(ctx, mergeTarget, branch.Name)
+			if err != nil {
+				logVerbose("マージ状態の判定エラー: %s - %v", branch.Name, err)
+				plan.Warnings = append(plan.Warnings, fmt.Sprintf("failed to detect merge status of %s: %v", branch.Name, err))
+			}
+		}
+
+		decision := classifyBranch(branch, defaultBranch, target, status, filter, options)
+		if decision.Action == BranchActionDelete && (plan.BareLayout || plan.DefaultWorktree != "" || (remotes.Fork() && target != defaultBranch)) {
+			// チェックアウトしないため git branch -d は実行中のワークツリーのHEADに対して判定して拒否することがある
+			// フォーク元にマージ済みでも、ローカルのデフォルトブランチに含まれていなければ同様に拒否する
+			// マージ済みであることは確認できているため強制削除する（プルする場合は適用時にプル後の状態で再確認する）
+			decision.Action = BranchActionForceDelete
+		}
+		if status != MergeStatusUnmerged && decision.Action != BranchActionSkip {
+			decision.MergedInto = target
+		}
+		if wt, ok := checkedOut[branch.Name]; ok && decision.Action != BranchActionSkip {
+			r.planWorktreeRemoval(ctx, &decision, wt, status, plan, options)
+		}
+		decision.SHA = branch.SHA
+		decision.CommitTime = branch.CommitTime
+		decision.Subject = branch.Subject
+		decision.Upstream = branch.Upstream
+		decision.UpstreamTrack = branch.UpstreamTrack
+		if branch.Name != defaultBranch {
+			ahead, behind, err := r.CountAheadBehind(ctx, mergeTarget, branch.Name)
+			if err != nil {
+				logVerbose("先行・遅行コミット数の取得エラー: %s - %v", branch.Name, err)
+			}
+			decision.Ahead, decision.Behind = ahead, behind
+		}
+		logVerbose("ブランチ判定: %s -> %s (%s)", decision.Branch, decision.Action, decision.Reason)
+		plan.Branches = append(plan.Branches, decision)
+	}
+
+	return plan, nil
+}
+
+// planWorktreeRemoval は別のワークツリーでチェックアウトされているブランチの扱いを決定します
+// --remove-worktrees指定時とベアリポジトリ＋ワークツリー構成では、マージ済みで変更のない
+// ワークツリーを削除してからブランチを削除します。それ以外の場合は削除できないためスキップします
+func (r *Repository) planWorktreeRemoval(ctx context.Context, decision *BranchDecision, wt Worktree, status MergeStatus, plan *CleanupPlan, options CleanupOptions) {
+	logVerbose := newVerboseLogger(options.Verbose)
+	decision.Worktree = wt.Path
+
+	// 実行中のワークツリーは削除しない
+	current := plan.Repository != nil && wt.Path == plan.Repository.TopLevel
+	removable := (options.RemoveWorktrees || plan.BareLayout) && status != MergeStatusUnmerged && !wt.Locked && !current
+	if removable {
+		clean, err := r.IsWorktreeClean(ctx, wt.Path)
+		if err != nil {
+			logVerbose("ワークツリーの状態確認エラー: %s - %v", wt.Path, err)
+			plan.Warnings = append(plan.Warnings, fmt.Sprintf("failed to check worktree %s: %v", wt.Path, err))
+		}
+		if !clean {
+			logVerbose("未コミットの変更があるためワークツリーを削除しません: %s", wt.Path)
+		}
+		removable = err == nil && clean
+	}
+
+	if removable {
+		decision.RemoveWorktree = true
+		decision.Reason += fmt.Sprintf(", removes worktree %s", wt.Path)
+		return
+	}
+
+	decision.Action = BranchActionSkip
+	decision.Reason = worktreeReason(wt.Path)
+	decision.Locked = true
+}
+
+// ApplyCleanupPlan は実行計画を適用します
+// 計画作成後にブランチの先端が移動している場合は何も変更せずにエラーを返します
+// ctxがキャンセルされた場合は、それまでに完了した処理を含む結果とエラーを返します
+func (r *Repository) ApplyCleanupPlan(ctx context.Context, plan *CleanupPlan, options CleanupOptions) (*CleanupResult, error) {
+	logVerbose := newVerboseLogger(options.Verbose)
+
+	if plan.Version != CleanupPlanVersion {
+		return nil, NewGitError("apply", ErrStalePlan).WithMessage(fmt.Sprintf("unsupported plan version %d", plan.Version))
+	}
+
+	result := &CleanupResult{
+		DefaultBranch: plan.DefaultBranch,
+		MergeTarget:   plan.MergeTarget,
+		WasDryRun:     options.DryRun,
+	}
+	for _, warning := range plan.Warnings {
+		result.Errors = append(result.Errors, NewGitError("cleanup", fmt.Errorf("%s", warning)))
+	}
+
+	// 1. 計画作成後にブランチが変更されていないかの確認
+	logVerbose("実行計画の検証を開始")
+	if err := r.verifyPlan(ctx, plan); err != nil {
+		return nil, err
+	}
+	logVerbose("実行計画の検証完了")
+
+	// 計画作成後にマージやリベースが開始された場合も何もせずに終了する
+	if err := r.CheckInProgress(ctx); err != nil {
+		return nil, err
+	}
+
+	// 計画作成後に保護設定が追加された場合でも保護ブランチは削除しない
+	if err := r.protectPlanBranches(ctx, plan, options); err != nil {
+		return nil, err
+	}
+
+	if options.DryRun {
+		// ドライランモードの場合は計画の内容を結果として返す
+		logVerbose("ドライランモードのため、ブランチ切り替え・プル・削除は行いません")
+		if plan.Checkout != nil {
+			result.DetachedFrom = plan.Checkout.FromCommit
+		}
+		for _, decision := range plan.Branches {
+			switch {
+			case decision.Protected:
+				result.ProtectedBranches = append(result.ProtectedBranches, decision.Branch)
+			case decision.Action == BranchActionSkip:
+				result.SkippedBranches = append(result.SkippedBranches, decision.Branch)
+			default:
+				result.DeletedBranches = append(result.DeletedBranches, decision.Branch)
+				if decision.RemoveWorktree {
+					result.RemovedWorktrees = append(result.RemovedWorktrees, decision.Worktree)
+				}
+			}
+			result.Decisions = append(result.Decisions, decision)
+		}
+		return result, nil
+	}
+
+	// 2. デフォルトブランチへの切り替え
+	var stashSHA string
+	if plan.Checkout != nil {
+		// 計画作成後に変更された可能性があるため、切り替え直前に改めて確認する
+		dirty, err := r.checkDirty(ctx, options)
+		if err != nil {
+			return nil, err
+		}
+		if dirty && options.Dirty == string(DirtyStash) {
+			logVerbose("未コミットの変更をスタッシュ")
+			stashSHA, err = r.Stash(ctx, time.Now())
+			if err != nil {
+				return nil, NewGitError("cleanup", err).WithMessage("failed to stash changes")
+			}
+			result.StashRef, _ = r.StashRef(ctx, stashSHA)
+			logVerbose("スタッシュを作成: %s (%s)", result.StashRef, shortSHA(stashSHA))
+		}
+
+		logVerbose("デフォルトブランチに切り替え: %s -> %s", plan.Checkout.head(), plan.Checkout.To)
+		if err := r.CheckoutBranch(ctx, plan.Checkout.To); err != nil {
+			// 切り替えられなかった場合は元のブランチのままなので、スタッシュをすぐに戻す
+			if stashSHA != "" {
+				if popErr := r.PopStash(ctx, stashSHA); popErr != nil {
+					result.Errors = append(result.Errors, popErr)
+				} else {
+					result.StashRestored = true
+				}
+			}
+			return result, NewGitError("cleanup", err).WithMessage("failed to switch to default branch")
+		}
+		result.CheckedOut = true
+		result.DetachedFrom = plan.Checkout.FromCommit
+		logVerbose("ブランチ切り替え完了")
+	}
+
+	// 3. プル処理（--pull=none または --no-pullが指定されていない場合）
+	if plan.Pull {
+		strategy := plan.PullStrategy
+		if strategy == "" {
+			strategy = PullFFOnly
+		}
+		logVerbose("プル処理を開始 (git pull, %s)", strategy)
+		pull := r.Pull
+		if plan.BareLayout || plan.DefaultWorktree != "" {
+			pull = func(ctx context.Context, strategy PullStrategy) error {
+				return r.updateBranch(ctx, plan.DefaultBranch, strategy)
+			}
+		}
+		if err := pull(ctx, strategy); err != nil {
+			// 中断された場合はそれまでの結果とともに終了する
+			if IsCanceled(err) {
+				return result, NewGitError("cleanup", err).WithMessage("pull interrupted")
+			}
+			// プル失敗は警告として扱い、処理を継続
+			logVerbose("プルエラー: %v", err)
+			result.Errors = append(result.Errors, NewGitError("cleanup", err).WithMessage("pull failed"))
+		} else {
+			result.Pulled = true
+			logVerbose("プル完了")
+		}
+	} else {
+		logVerbose("プル処理をスキップ")
+	}
+
+	// フォークのデフォルトブランチをフォーク元に追従させ、フォークにプッシュ（--sync-fork）
+	if plan.SyncFork && plan.Remotes != nil && plan.Remotes.Fork() {
+		logVerbose("フォークを同期: %s -> %s (%s)", plan.MergeTarget, plan.DefaultBranch, plan.Remotes.Push)
+		if err := r.syncFork(ctx, plan); err != nil {
+			if IsCanceled(err) {
+				return result, NewGitError("cleanup", err).WithMessage("fork sync interrupted")
+			}
+			logVerbose("フォークの同期エラー: %v", err)
+			result.Errors = append(result.Errors, NewGitError("cleanup", err).WithMessage("fork sync failed"))
+		} else {
+			result.ForkSynced = plan.Remotes.Push
+			logVerbose("フォークの同期完了")
+		}
+	}
+
+	// 4. ブランチの削除
+	logVerbose("ブランチ削除処理を開始")
+	for _, decision := range plan.Branches {
+		// 中断された場合は削除済みのブランチを結果に含めて終了する
+		if err := ctx.Err(); err != nil {
+			return result, NewGitError("cleanup", err).WithMessage("branch deletion interrupted")
+		}
+
+		if decision.Protected {
+			logVerbose("保護ブランチをスキップ: %s (%s)", decision.Branch, decision.Reason)
+			result.ProtectedBranches = append(result.ProtectedBranches, decision.Branch)
+			result.Decisions = append(result.Decisions, decision)
+			continue
+		}
+
+		if decision.Action == BranchActionSkip {
+			logVerbose("ブランチをスキップ: %s (%s)", decision.Branch, decision.Reason)
+			result.SkippedBranches = append(result.SkippedBranches, decision.Branch)
+			result.Decisions = append(result.Decisions, decision)
+			continue
+		}
+
+		// 強制削除では git branch -d による確認が行われないため、プルした場合はプル後のデフォルトブランチに
+		// マージ済みであることを改めて確認する（プルに失敗した場合などはリモート追跡ブランチにのみ含まれることがある）
+		if err := r.recheckMerged(ctx, plan, decision); err != nil {
+			if IsCanceled(err) {
+				return result, NewGitError("cleanup", err).WithMessage("branch deletion interrupted")
+			}
+			logVerbose("マージ済みの再確認エラー: %s - %v", decision.Branch, err)
+			result.Errors = append(result.Errors, NewGitError("cleanup", err).WithPath(decision.Branch))
+			result.SkippedBranches = append(result.SkippedBranches, decision.Branch)
+			decision.Action = BranchActionSkip
+			decision.Reason = err.Error()
+			result.Decisions = append(result.Decisions, decision)
+			continue
+		}
+
+		if decision.RemoveWorktree {
+			logVerbose("ワークツリーを削除: %s", decision.Worktree)
+			if err := r.RemoveWorktree(ctx, decision.Worktree); err != nil {
+				logVerbose("ワークツリー削除エラー: %s - %v", decision.Worktree, err)
+				result.Errors = append(result.Errors, NewGitError("cleanup", err).WithPath(decision.Branch))
+				result.SkippedBranches = append(result.SkippedBranches, decision.Branch)
+				decision.Action = BranchActionSkip
+				decision.Reason = fmt.Sprintf("%s (remove failed: %v)", worktreeReason(decision.Worktree), err)
+				result.Decisions = append(result.Decisions, decision)
+				continue
+			}
+			result.RemovedWorktrees = append(result.RemovedWorktrees, decision.Worktree)
+		}
+
+		logVerbose("ブランチ削除を試行: %s", decision.Branch)
+		if err := r.DeleteBranch(ctx, decision.Branch, decision.Action == BranchActionForceDelete); err != nil {
+			if IsCanceled(err) {
+				return result, NewGitError("cleanup", err).WithMessage("branch deletion interrupted")
+			}
+			logVerbose("ブランチ削除エラー: %s - %v", decision.Branch, err)
+			result.Errors = append(result.Errors, NewGitError("cleanup", err).WithPath(decision.Branch))
+			result.SkippedBranches = append(result.SkippedBranches, decision.Branch)
+			decision.Action = BranchActionSkip
+			decision.Reason = fmt.Sprintf("delete failed: %v", err)
+		} else {
+			logVerbose("ブランチ削除成功: %s", decision.Branch)
+			result.DeletedBranches = append(result.DeletedBranches, decision.Branch)
+		}
+		result.Decisions = append(result.Decisions, decision)
+	}
+
+	// 5. 元のブランチに戻り、スタッシュした変更を再適用
+	if plan.Checkout != nil && (options.Return || stashSHA != "") {
+		returned := r.returnToOriginal(ctx, plan.Checkout, result, logVerbose)
+		if stashSHA != "" {
+			r.restoreStash(ctx, returned, stashSHA, result, logVerbose)
+		}
+	}
+
+	logVerbose("クリーンアップ処理完了 - 削除: %d, スキップ: %d, エラー: %d", len(result.DeletedBranches), len(result.SkippedBranches), len(result.Errors))
+
+	return result, nil
+}
+
+// checkDirty は未コミットの変更の有無を確認し、--dirty=abort の場合はエラーを返します
+func (r *Repository) checkDirty(ctx context.Context, options CleanupOptions) (bool, error) {
+	dirty, err := r.IsDirty(ctx)
+	if err != nil {
+		return false, NewGitError("cleanup", err)
+	}
+
+	policy, err := ParseDirtyPolicy(options.Dirty)
+	if err != nil {
+		return false, err
+	}
+	if dirty && policy == DirtyAbort {
+		return true, NewGitError("cleanup", ErrDirtyWorktree).WithMessage("commit or stash your changes, or use --dirty=stash or --dirty=keep")
+	}
+	return dirty, nil
+}
+
+// checkOrphaned はdetached HEADのコミットがどの参照からも到達できない場合に警告を記録します
+// デフォルトブランチに切り替えた後は、reflogの期限が切れるとコミットが失われるためです
+func (r *Repository) checkOrphaned(ctx context.Context, plan *CleanupPlan, logVerbose func(string, ...interface{})) {
+	commit := plan.Checkout.FromCommit
+	reachable, err := r.IsReachable(ctx, commit)
+	if err != nil {
+		logVerbose("detached HEADの到達可能性の確認エラー: %v", err)
+		plan.Warnings = append(plan.Warnings, fmt.Sprintf("failed to check whether detached HEAD %s is reachable: %v", shortSHA(commit), err))
+		return
+	}
+	if reachable {
+		return
+	}
+
+	logVerbose("detached HEADのコミットはどのブランチからも到達できません: %s", shortSHA(commit))
+	plan.Checkout.Orphaned = true
+	plan.Warnings = append(plan.Warnings, fmt.Sprintf("detached HEAD %s is not reachable from any branch or tag; run \"git branch <name> %s\" to keep it", shortSHA(commit), shortSHA(commit)))
+}
+
+// returnToOriginal はクリーンアップ前にいたブランチ（detached HEADの場合はコミット）に戻ります
+// 元のブランチが削除された場合はデフォルトブランチに留まり、falseを返します
+func (r *Repository) returnToOriginal(ctx context.Context, checkout *CheckoutAction, result *CleanupResult, logVerbose func(string, ...interface{})) bool {
+	if checkout.Detached {
+		logVerbose("元のコミットに戻る: %s", shortSHA(checkout.FromCommit))
+		if _, err := r.ExecuteCommand(ctx, "checkout", "--detach", checkout.FromCommit); err != nil {
+			result.Errors = append(result.Errors, NewGitError("checkout", err).WithMessage(fmt.Sprintf("failed to return to %s", shortSHA(checkout.FromCommit))))
+			return false
+		}
+		result.ReturnedTo = checkout.FromCommit
+		return true
+	}
+
+	exists, err := r.LocalBranchExists(ctx, checkout.From)
+	if err != nil || !exists {
+		logVerbose("元のブランチは削除されたため、デフォルトブランチに留まります: %s", checkout.From)
+		return false
+	}
+
+	logVerbose("元のブランチに戻る: %s", checkout.From)
+	if err := r.CheckoutBranch(ctx, checkout.From); err != nil {
+		result.Errors = append(result.Errors, NewGitError("cleanup", err).WithMessage(fmt.Sprintf("failed to return to %s", checkout.From)))
+		return false
+	}
+	result.ReturnedTo = checkout.From
+	return true
+}
+
+// restoreStash は元のブランチに戻った後、自動スタッシュを再適用します
+// 元のブランチに戻れなかった場合や再適用に失敗した場合は、スタッシュを残して警告を記録します
+func (r *Repository) restoreStash(ctx context.Context, returned bool, sha string, result *CleanupResult, logVerbose func(string, ...interface{})) {
+	if !returned {
+		logVerbose("元のブランチに戻れないため、スタッシュを残します: %s", result.StashRef)
+		result.Errors = append(result.Errors, NewGitError("cleanup", fmt.Errorf("could not return to the original branch; your changes are kept in %s", result.StashRef)))
+		return
+	}
+
+	logVerbose("スタッシュを再適用: %s", result.StashRef)
+	if err := r.PopStash(ctx, sha); err != nil {
+		result.Errors = append(result.Errors, NewGitError("cleanup", err).WithMessage(fmt.Sprintf("your changes are kept in %s", result.StashRef)))
+		return
+	}
+	result.StashRestored = true
+}
+
+// recheckMerged はプル後のデフォルトブランチに対して、強制削除するブランチがマージ済みであることを確認します
+// プルしない場合、フォーク運用（ローカルのデフォルトブランチはフォーク元に追従しない）、
+// および未マージのまま強制削除する場合（--forceや対話モードでの選択）は確認しません
+func (r *Repository) recheckMerged(ctx context.Context, plan *CleanupPlan, decision BranchDecision) error {
+	if decision.Action != BranchActionForceDelete || decision.MergedInto == "" || !plan.Pull {
+		return nil
+	}
+	if plan.Remotes != nil && plan.Remotes.Fork() {
+		return nil
+	}
+
+	status, err := r.DetectMergeStatus(ctx, plan.DefaultBranch, decision.Branch)
+	if err != nil {
+		return err
+	}
+	if status == MergeStatusUnmerged {
+		return fmt.Errorf("not merged into %s after pull", plan.DefaultBranch)
+	}
+	return nil
+}
+
+// updateBranch はチェックアウトせずにブランチをリモートの最新状態に更新します
+// ブランチがいずれかのワークツリーでチェックアウトされている場合はそのワークツリーで指定した方法でプルします
+// （チェックアウト中のブランチはフェッチで直接更新できないため）
+// チェックアウトされていない場合はリベース・マージを行えないため、プルの方法にかかわらずfast-forwardのみ行います
+func (r *Repository) updateBranch(ctx context.Context, branch string, strategy PullStrategy) error {
+	worktrees, err := r.ListWorktrees(ctx)
+	if err != nil {
+		return err
+	}
+	for _, wt := range worktrees {
+		if wt.Branch == branch {
+			return r.worktree(wt.Path).Pull(ctx, strategy)
+		}
+	}
+	return r.FetchBranch(ctx, branch)
+}
+
+// verifyPlan は現在のリポジトリの状態が計画作成時から変わっていないか確認します
+func (r *Repository) verifyPlan(ctx context.Context, plan *CleanupPlan) error {
+	// 未知の処理内容（計画ファイルの手動編集による入力ミスなど）を削除として扱わない
+	for _, decision := range plan.Branches {
+		switch decision.Action {
+		case BranchActionDelete, BranchActionForceDelete, BranchActionSkip:
+		default:
+			return NewGitError("apply", fmt.Errorf("unknown action %q (must be delete, force-delete or skip)", decision.Action)).WithPath(decision.Branch)
+		}
+	}
+
+	// 別のリポジトリで作成された計画は適用しない（同じリポジトリのワークツリーは許可する）
+	if plan.Repository != nil {
+		info, err := r.DetectRepository(ctx)
+		if err != nil {
+			return NewGitError("apply", err)
+		}
+		if info.CommonDir != plan.Repository.CommonDir {
+			return NewGitError("apply", ErrStalePlan).WithMessage(fmt.Sprintf("plan was created for repository %s", plan.Repository.CommonDir))
+		}
+	}
+
+	branches, err := r.ListBranchInfos(ctx)
+	if err != nil {
+		return NewGitError("apply", err)
+	}
+	tips := make(map[string]string, len(branches))
+	for _, branch := range branches {
+		tips[branch.Name] = branch.SHA
+	}
+
+	for _, decision := range plan.Branches {
+		sha, ok := tips[decision.Branch]
+		if !ok {
+			return NewGitError("apply", ErrStalePlan).WithMessage(fmt.Sprintf("branch %s no longer exists", decision.Branch))
+		}
+		if sha != decision.SHA {
+			return NewGitError("apply", ErrStalePlan).WithMessage(fmt.Sprintf("branch %s moved from %s to %s", decision.Branch, shortSHA(decision.SHA), shortSHA(sha)))
+		}
+	}
+
+	if plan.Checkout != nil {
+		head, err := r.GetHead(ctx)
+		if err != nil {
+			return NewGitError("apply", err)
+		}
+		if head.Detached != plan.Checkout.Detached || head.Branch != plan.Checkout.From {
+			return NewGitError("apply", ErrStalePlan).WithMessage(fmt.Sprintf("current branch changed from %s to %s", plan.Checkout.head(), head))
+		}
+		if plan.Checkout.Detached && head.Commit != plan.Checkout.FromCommit {
+			return NewGitError("apply", ErrStalePlan).WithMessage(fmt.Sprintf("HEAD moved from %s to %s", shortSHA(plan.Checkout.FromCommit), shortSHA(head.Commit)))
+		}
+	}
+
+	return nil
+}
+
+// loadProtectedPatterns は現在のリポジトリの保護ブランチのパターンにオプションの指定を加えて返します
+// gitc.protect は設定レイヤー経由でオプションにも含まれるため、重複するパターンは1つにまとめます
+func (r *Repository) loadProtectedPatterns(ctx context.Context, options CleanupOptions) ([]string, error) {
+	patterns, err := r.LoadProtectedPatterns(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, pattern := range options.ProtectPatterns {
+		if !slices.Contains(patterns, pattern) {
+			patterns = append(patterns, pattern)
+		}
+	}
+	return patterns, nil
+}
+
+// protectPlanBranches は現在の保護設定にマッチするブランチを計画の削除対象から外します
+func (r *Repository) protectPlanBranches(ctx context.Context, plan *CleanupPlan, options CleanupOptions) error {
+	patterns, err := r.loadProtectedPatterns(ctx, options)
+	if err != nil {
+		return NewGitError("apply", err)
+	}
+	protected, err := ParseBranchPatterns(patterns)
+	if err != nil {
+		return NewGitError("apply", err).WithMessage("invalid protected branch pattern")
+	}
+
+	for i := range plan.Branches {
+		decision := &plan.Branches[i]
+		if decision.Protected {
+			continue
+		}
+		if p := matchBranchPatterns(decision.Branch, protected); p != nil {
+			decision.Action = BranchActionSkip
+			decision.Reason = fmt.Sprintf("protected branch (matches %q)", p)
+			decision.Locked = true
+			decision.Protected = true
+		}
+	}
+	return nil
+}
+
+// shortSHA はコミットハッシュを表示用に短縮します
+func shortSHA(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
@@ -0,0 +1,322 @@
+package git
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPlanCleanup(t *testing.T) {
+	t.Parallel()
+
+	dir, cleanup := createTestGitRepo(t)
+	defer cleanup()
+	repo := NewRepository(dir)
+
+	runGit(t, dir, "checkout", "-b", "feature")
+	sha := runGit(t, dir, "rev-parse", "HEAD")
+
+	plan, err := repo.PlanCleanup(t.Context(), CleanupOptions{NoPull: true})
+	if err != nil {
+		t.Fatalf("PlanCleanup() error = %v", err)
+	}
+
+	if plan.DefaultBranch != "main" {
+		t.Errorf("DefaultBranch = %s, want main", plan.DefaultBranch)
+	}
+	if plan.Checkout == nil || plan.Checkout.From != "feature" || plan.Checkout.To != "main" {
+		t.Errorf("Checkout = %+v, want feature -> main", plan.Checkout)
+	}
+	if plan.Pull {
+		t.Error("Pull should be false when NoPull is set")
+	}
+
+	for _, decision := range plan.Branches {
+		if decision.SHA != sha {
+			t.Errorf("SHA for %s = %s, want %s", decision.Branch, decision.SHA, sha)
+		}
+	}
+
+	// 計画作成だけではブランチが切り替わらないこと
+	current, err := repo.GetCurrentBranch(t.Context())
+	if err != nil {
+		t.Fatalf("GetCurrentBranch() error = %v", err)
+	}
+	if current != "feature" {
+		t.Errorf("current branch = %s, want feature", current)
+	}
+}
+
+func TestApplyCleanupPlan(t *testing.T) {
+	t.Parallel()
+
+	t.Run("計画どおりに適用される", func(t *testing.T) {
+		dir, cleanup := createTestGitRepo(t)
+		defer cleanup()
+		repo := NewRepository(dir)
+
+		runGit(t, dir, "branch", "feature")
+
+		plan, err := repo.PlanCleanup(t.Context(), CleanupOptions{NoPull: true})
+		if err != nil {
+			t.Fatalf("PlanCleanup() error = %v", err)
+		}
+
+		// JSON経由で往復しても同じ計画として適用できること
+		data, err := json.Marshal(plan)
+		if err != nil {
+			t.Fatalf("json.Marshal() error = %v", err)
+		}
+		var decoded CleanupPlan
+		if err := json.Unmarshal(data, &decoded); err != nil {
+			t.Fatalf("json.Unmarshal() error = %v", err)
+		}
+
+		result, err := repo.ApplyCleanupPlan(t.Context(), &decoded, CleanupOptions{})
+		if err != nil {
+			t.Fatalf("ApplyCleanupPlan() error = %v", err)
+		}
+		if len(result.DeletedBranches) != 1 || result.DeletedBranches[0] != "feature" {
+			t.Errorf("DeletedBranches = %v, want [feature]", result.DeletedBranches)
+		}
+	})
+
+	t.Run("ブランチの先端が移動していたら適用しない", func(t *testing.T) {
+		dir, cleanup := createTestGitRepo(t)
+		defer cleanup()
+		repo := NewRepository(dir)
+
+		runGit(t, dir, "branch", "feature")
+
+		plan, err := repo.PlanCleanup(t.Context(), CleanupOptions{NoPull: true})
+		if err != nil {
+			t.Fatalf("PlanCleanup() error = %v", err)
+		}
+
+		// 計画作成後にブランチを進める
+		runGit(t, dir, "checkout", "feature")
+		runGit(t, dir, "commit", "--allow-empty", "-m", "new work")
+		runGit(t, dir, "checkout", "main")
+
+		_, err = repo.ApplyCleanupPlan(t.Context(), plan, CleanupOptions{})
+		if !IsStalePlan(err) {
+			t.Fatalf("ApplyCleanupPlan() error = %v, want ErrStalePlan", err)
+		}
+
+		branches, err := repo.ListLocalBranches(t.Context())
+		if err != nil {
+			t.Fatalf("ListLocalBranches() error = %v", err)
+		}
+		if len(branches) != 2 {
+			t.Errorf("ListLocalBranches() = %v, want no branches deleted", branches)
+		}
+	})
+	t.Run("未知の処理内容を含む計画は適用しない", func(t *testing.T) {
+		dir, cleanup := createTestGitRepo(t)
+		defer cleanup()
+		repo := NewRepository(dir)
+
+		runGit(t, dir, "branch", "feature")
+		runGit(t, dir, "branch", "keep")
+
+		plan, err := repo.PlanCleanup(t.Context(), CleanupOptions{NoPull: true})
+		if err != nil {
+			t.Fatalf("PlanCleanup() error = %v", err)
+		}
+		for i := range plan.Branches {
+			if plan.Branches[i].Branch == "keep" {
+				plan.Branches[i].Action = "kep"
+			}
+		}
+
+		if _, err := repo.ApplyCleanupPlan(t.Context(), plan, CleanupOptions{}); err == nil || !strings.Contains(err.Error(), `unknown action "kep"`) {
+			t.Fatalf("ApplyCleanupPlan() error = %v, want unknown action error", err)
+		}
+
+		branches, err := repo.ListLocalBranches(t.Context())
+		if err != nil {
+			t.Fatalf("ListLocalBranches() error = %v", err)
+		}
+		if len(branches) != 3 {
+			t.Errorf("ListLocalBranches() = %v, want no branches deleted", branches)
+		}
+	})
+
+	t.Run("別のリポジトリで作成した計画は適用しない", func(t *testing.T) {
+		dir, cleanup := createTestGitRepo(t)
+		defer cleanup()
+		other, _ := createTestGitRepo(t)
+
+		runGit(t, dir, "branch", "feature")
+		runGit(t, other, "branch", "feature")
+
+		plan, err := NewRepository(dir).PlanCleanup(t.Context(), CleanupOptions{NoPull: true})
+		if err != nil {
+			t.Fatalf("PlanCleanup() error = %v", err)
+		}
+
+		_, err = NewRepository(other).ApplyCleanupPlan(t.Context(), plan, CleanupOptions{})
+		if !IsStalePlan(err) {
+			t.Fatalf("ApplyCleanupPlan() error = %v, want ErrStalePlan", err)
+		}
+	})
+
+	t.Run("キャンセルされた場合はブランチを削除しない", func(t *testing.T) {
+		dir, cleanup := createTestGitRepo(t)
+		defer cleanup()
+		repo := NewRepository(dir)
+
+		runGit(t, dir, "branch", "feature")
+
+		plan, err := repo.PlanCleanup(t.Context(), CleanupOptions{NoPull: true})
+		if err != nil {
+			t.Fatalf("PlanCleanup() error = %v", err)
+		}
+
+		ctx, cancel := context.WithCancel(t.Context())
+		cancel()
+
+		_, err = repo.ApplyCleanupPlan(ctx, plan, CleanupOptions{})
+		if !IsCanceled(err) {
+			t.Fatalf("ApplyCleanupPlan() error = %v, want canceled error", err)
+		}
+
+		branches, err := repo.ListLocalBranches(t.Context())
+		if err != nil {
+			t.Fatalf("ListLocalBranches() error = %v", err)
+		}
+		if len(branches) != 2 {
+			t.Errorf("ListLocalBranches() = %v, want no branches deleted", branches)
+		}
+	})
+}
+
+func TestPlanCleanupCanceled(t *testing.T) {
+	t.Parallel()
+
+	dir, cleanup := createTestGitRepo(t)
+	defer cleanup()
+
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+
+	plan, err := NewRepository(dir).PlanCleanup(ctx, CleanupOptions{NoPull: true})
+	if !IsCanceled(err) {
+		t.Fatalf("PlanCleanup() error = %v, want canceled error", err)
+	}
+	if plan != nil {
+		t.Errorf("PlanCleanup() plan = %+v, want nil", plan)
+	}
+}
+
+func TestPlanCleanupFromWorktree(t *testing.T) {
+	t.Parallel()
+
+	dir, cleanup := createTestGitRepo(t)
+	defer cleanup()
+
+	runGit(t, dir, "branch", "feature")
+	worktree := filepath.Join(t.TempDir(), "wt")
+	runGit(t, dir, "worktree", "add", "-b", "wt", worktree)
+	sub := filepath.Join(worktree, "sub")
+	if err := os.Mkdir(sub, 0755); err != nil {
+		t.Fatalf("Failed to create subdirectory: %v", err)
+	}
+
+	// リンクされたワークツリーのサブディレクトリからでも同じリポジトリのブランチを判定できること
+	repo, err := OpenRepository(t.Context(), sub)
+	if err != nil {
+		t.Fatalf("OpenRepository() error = %v", err)
+	}
+	plan, err := repo.PlanCleanup(t.Context(), CleanupOptions{NoPull: true, DefaultBranch: "main"})
+	if err != nil {
+		t.Fatalf("PlanCleanup() error = %v", err)
+	}
+
+	if plan.Repository == nil || plan.Repository.CommonDir != resolvePath(t, filepath.Join(dir, ".git")) {
+		t.Errorf("Repository = %+v, want common dir of %s", plan.Repository, dir)
+	}
+	found := false
+	for _, decision := range plan.Branches {
+		if decision.Branch == "feature" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("Branches = %+v, want feature to be included", plan.Branches)
+	}
+}
+
+func TestPlanCleanupDetachedHead(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		setup        func(t *testing.T, dir string)
+		wantOrphaned bool
+	}{
+		{
+			name: "ブランチから到達できるコミット",
+			setup: func(t *testing.T, dir string) {
+				runGit(t, dir, "commit", "--allow-empty", "-m", "second")
+				runGit(t, dir, "checkout", "--detach", "HEAD~1")
+			},
+		},
+		{
+			name: "どの参照からも到達できないコミットは警告する",
+			setup: func(t *testing.T, dir string) {
+				runGit(t, dir, "checkout", "--detach")
+				runGit(t, dir, "commit", "--allow-empty", "-m", "detached work")
+			},
+			wantOrphaned: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			dir, cleanup := createTestGitRepo(t)
+			defer cleanup()
+			repo := NewRepository(dir)
+			tt.setup(t, dir)
+			commit := runGit(t, dir, "rev-parse", "HEAD")
+
+			plan, err := repo.PlanCleanup(t.Context(), CleanupOptions{NoPull: true})
+			if err != nil {
+				t.Fatalf("PlanCleanup() error = %v", err)
+			}
+
+			// "HEAD" をブランチ名として扱わないこと
+			if plan.CurrentBranch != "" {
+				t.Errorf("CurrentBranch = %q, want empty", plan.CurrentBranch)
+			}
+			for _, decision := range plan.Branches {
+				if decision.Branch == "HEAD" {
+					t.Errorf("Branches should not include HEAD: %+v", decision)
+				}
+			}
+			want := CheckoutAction{Detached: true, FromCommit: commit, Orphaned: tt.wantOrphaned, To: "main"}
+			if plan.Checkout == nil || *plan.Checkout != want {
+				t.Fatalf("Checkout = %+v, want %+v", plan.Checkout, want)
+			}
+			if warned := len(plan.Warnings) > 0 && contains(plan.Warnings[0], "not reachable"); warned != tt.wantOrphaned {
+				t.Errorf("Warnings = %v, want orphaned warning %v", plan.Warnings, tt.wantOrphaned)
+			}
+
+			result, err := repo.ApplyCleanupPlan(t.Context(), plan, CleanupOptions{})
+			if err != nil {
+				t.Fatalf("ApplyCleanupPlan() error = %v", err)
+			}
+			if result.DetachedFrom != commit {
+				t.Errorf("DetachedFrom = %s, want %s", result.DetachedFrom, commit)
+			}
+			if got := runGit(t, dir, "rev-parse", "--abbrev-ref", "HEAD"); got != "main" {
+				t.Errorf("HEAD = %s, want main", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// InProgressError はリポジトリで別のGit操作が進行中のため処理を開始できないことを表します
+type InProgressError struct {
+	Operation string // 進行中の操作（例: "rebase"）
+	Path      string // 検出に使用したGitディレクトリ内のパス
+	Hint      string // 解消方法
+}
+
+// Error はerrorインターフェースを実装します
+func (e *InProgressError) Error() string {
+	return fmt.Sprintf("%s is in progress (%s exists); %s", e.Operation, e.Path, e.Hint)
+}
+
+// Unwrap はErrOperationInProgressを返し、errors.Isで判定できるようにします
+func (e *InProgressError) Unwrap() error {
+	return ErrOperationInProgress
+}
+
+// inProgressStates はGitディレクトリ内で進行中の操作を示すファイルと、その解消方法です
+// 上から順に確認し、最初に見つかったものを報告します
+var inProgressStates = []struct {
+	name      string
+	operation string
+	hint      string
+}{
+	{name: "rebase-merge", operation: "a rebase", hint: `finish it with "git rebase --continue" or abort it with "git rebase --abort"`},
+	{name: "rebase-apply", operation: "a rebase or git am", hint: `finish it with "git rebase --continue" / "git am --continue" or abort it with "--abort"`},
+	{name: "MERGE_HEAD", operation: "a merge", hint: `commit the merge or abort it with "git merge --abort"`},
+	{name: "CHERRY_PICK_HEAD", operation: "a cherry-pick", hint: `finish it with "git cherry-pick --continue" or abort it with "git cherry-pick --abort"`},
+	{name: "BISECT_LOG", operation: "a bisect", hint: `end it with "git bisect reset"`},
+	{name: "index.lock", operation: "another git process", hint: "wait for it to finish, or remove the lock file if no git process is running"},
+}
+
+// CheckInProgress はマージ・リベース・チェリーピック・bisectなどの操作が進行中でないか確認します
+// 進行中の場合はブランチの切り替えや削除で状況を悪化させないよう *InProgressError を返します
+func (r *Repository) CheckInProgress(ctx context.Context) error {
+	info, err := r.DetectRepository(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, state := range inProgressStates {
+		path := filepath.Join(info.GitDir, state.name)
+		if _, err := os.Stat(path); err == nil {
+			return &InProgressError{Operation: state.operation, Path: path, Hint: state.hint}
+		} else if !os.IsNotExist(err) {
+			return NewGitError("preflight", err).WithPath(path)
+		}
+	}
+	return nil
+}
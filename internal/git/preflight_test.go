@@ -0,0 +1,105 @@
+package git
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCheckInProgress(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name          string
+		state         string // Gitディレクトリに作成するファイル・ディレクトリ
+		dir           bool
+		wantOperation string
+	}{
+		{name: "進行中の操作なし"},
+		{name: "リベース中（merge）", state: "rebase-merge", dir: true, wantOperation: "a rebase"},
+		{name: "リベース中（apply）", state: "rebase-apply", dir: true, wantOperation: "a rebase or git am"},
+		{name: "マージ中", state: "MERGE_HEAD", wantOperation: "a merge"},
+		{name: "チェリーピック中", state: "CHERRY_PICK_HEAD", wantOperation: "a cherry-pick"},
+		{name: "bisect中", state: "BISECT_LOG", wantOperation: "a bisect"},
+		{name: "インデックスがロックされている", state: "index.lock", wantOperation: "another git process"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			dir, cleanup := createTestGitRepo(t)
+			defer cleanup()
+
+			if tt.state != "" {
+				path := filepath.Join(dir, ".git", tt.state)
+				var err error
+				if tt.dir {
+					err = os.Mkdir(path, 0755)
+				} else {
+					err = os.WriteFile(path, nil, 0644)
+				}
+				if err != nil {
+					t.Fatalf("Failed to create %s: %v", tt.state, err)
+				}
+			}
+
+			err := NewRepository(dir).CheckInProgress(t.Context())
+			if tt.wantOperation == "" {
+				if err != nil {
+					t.Errorf("CheckInProgress() error = %v, want nil", err)
+				}
+				return
+			}
+
+			var inProgress *InProgressError
+			if !errors.As(err, &inProgress) {
+				t.Fatalf("CheckInProgress() error = %v, want *InProgressError", err)
+			}
+			if inProgress.Operation != tt.wantOperation {
+				t.Errorf("Operation = %q, want %q", inProgress.Operation, tt.wantOperation)
+			}
+			if !IsOperationInProgress(err) {
+				t.Error("IsOperationInProgress() = false, want true")
+			}
+		})
+	}
+}
+
+func TestPlanCleanup_MergeInProgress(t *testing.T) {
+	t.Parallel()
+
+	dir, cleanup := createTestGitRepo(t)
+	defer cleanup()
+
+	// コンフリクトしたマージの途中の状態を作る
+	runGit(t, dir, "checkout", "-b", "feature")
+	if err := os.WriteFile(filepath.Join(dir, "test.txt"), []byte("feature"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	runGit(t, dir, "commit", "-am", "feature change")
+	runGit(t, dir, "checkout", "main")
+	if err := os.WriteFile(filepath.Join(dir, "test.txt"), []byte("main"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	runGit(t, dir, "commit", "-am", "main change")
+	runGit(t, dir, "branch", "merged")
+	if _, err := NewRepository(dir).ExecuteCommand(t.Context(), "merge", "feature"); err == nil {
+		t.Fatal("merge should conflict")
+	}
+
+	_, err := NewRepository(dir).ExecuteCleanup(t.Context(), CleanupOptions{NoPull: true})
+	if !IsOperationInProgress(err) {
+		t.Fatalf("ExecuteCleanup() error = %v, want operation in progress", err)
+	}
+
+	// 何も変更されていないこと
+	exists, err := NewRepository(dir).BranchExists(t.Context(), "merged")
+	if err != nil {
+		t.Fatalf("BranchExists() error = %v", err)
+	}
+	if !exists {
+		t.Error("merged branch should not be deleted while a merge is in progress")
+	}
+}
@@ -0,0 +1,81 @@
+package git
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ProtectFileName はリポジトリにコミットする保護ブランチ一覧ファイルの名前です
+// 1行に1つのパターンを記述し、"#" で始まる行と空行は無視されます
+const ProtectFileName = ".gitcprotect"
+
+// protectConfigKey は保護ブランチのパターンを設定するgit configのキーです
+const protectConfigKey = "gitc.protect"
+
+// LoadProtectedPatterns はgit configとリポジトリの保護ブランチファイルから保護パターンを読み込みます
+func (r *Repository) LoadProtectedPatterns(ctx context.Context) ([]string, error) {
+	// ベアリポジトリには作業ツリーがないため保護ブランチファイルは読み込まない
+	info, err := r.DetectRepository(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	patterns, err := r.getConfigValues(ctx, protectConfigKey)
+	if err != nil {
+		return nil, NewGitError("load-protected", err)
+	}
+
+	if info.TopLevel == "" {
+		return patterns, nil
+	}
+
+	filePatterns, err := readProtectFile(filepath.Join(info.TopLevel, ProtectFileName))
+	if err != nil {
+		return nil, NewGitError("load-protected", err).WithPath(ProtectFileName)
+	}
+
+	return append(patterns, filePatterns...), nil
+}
+
+// getConfigValues はgit configの複数値キーの値をすべて返します（未設定の場合は空）
+func (r *Repository) getConfigValues(ctx context.Context, key string) ([]string, error) {
+	result, err := r.ExecuteCommand(ctx, "config", "--get-all", key)
+	if err != nil {
+		// キーが存在しない場合は終了コード1でエラー出力なしとなる
+		if result != nil && result.Output == "" && result.Error == "" {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return filterEmptyStrings(strings.Split(result.Output, "\n")), nil
+}
+
+// readProtectFile は保護ブランチファイルを読み込みます（存在しない場合は空）
+func readProtectFile(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	var patterns []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		patterns = append(patterns, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return patterns, nil
+}
@@ -0,0 +1,118 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLoadProtectedPatterns(t *testing.T) {
+	t.Parallel()
+
+	dir, cleanup := createTestGitRepo(t)
+	defer cleanup()
+	repo := NewRepository(dir)
+
+	// 未設定の場合は空
+	patterns, err := repo.LoadProtectedPatterns(t.Context())
+	if err != nil {
+		t.Fatalf("LoadProtectedPatterns() error = %v", err)
+	}
+	if len(patterns) != 0 {
+		t.Errorf("LoadProtectedPatterns() = %v, want empty", patterns)
+	}
+
+	runGit(t, dir, "config", "--add", "gitc.protect", "develop")
+	runGit(t, dir, "config", "--add", "gitc.protect", "staging")
+	content := "# 長期運用ブランチ\nrelease/*\n\n  re:^env/  \n"
+	if err := os.WriteFile(filepath.Join(dir, ProtectFileName), []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write %s: %v", ProtectFileName, err)
+	}
+
+	patterns, err = repo.LoadProtectedPatterns(t.Context())
+	if err != nil {
+		t.Fatalf("LoadProtectedPatterns() error = %v", err)
+	}
+	want := []string{"develop", "staging", "release/*", "re:^env/"}
+	if !reflect.DeepEqual(patterns, want) {
+		t.Errorf("LoadProtectedPatterns() = %v, want %v", patterns, want)
+	}
+}
+
+func TestLoadProtectedPatterns_WithOptions(t *testing.T) {
+	t.Parallel()
+
+	dir, cleanup := createTestGitRepo(t)
+	defer cleanup()
+	runGit(t, dir, "config", "--add", "gitc.protect", "develop")
+
+	// 設定レイヤーが読み込んだ gitc.protect がオプションにも含まれる場合に重複しないこと
+	patterns, err := NewRepository(dir).loadProtectedPatterns(t.Context(), CleanupOptions{ProtectPatterns: []string{"develop", "staging"}})
+	if err != nil {
+		t.Fatalf("loadProtectedPatterns() error = %v", err)
+	}
+	if want := []string{"develop", "staging"}; !reflect.DeepEqual(patterns, want) {
+		t.Errorf("loadProtectedPatterns() = %v, want %v", patterns, want)
+	}
+}
+
+func TestExecuteCleanup_ProtectedBranches(t *testing.T) {
+	t.Parallel()
+
+	dir, cleanup := createTestGitRepo(t)
+	defer cleanup()
+	repo := NewRepository(dir)
+
+	for _, branch := range []string{"develop", "release/1.0", "feature"} {
+		runGit(t, dir, "branch", branch)
+	}
+	runGit(t, dir, "config", "--add", "gitc.protect", "develop")
+	if err := os.WriteFile(filepath.Join(dir, ProtectFileName), []byte("release/*\n"), 0644); err != nil {
+		t.Fatalf("Failed to write %s: %v", ProtectFileName, err)
+	}
+
+	result, err := repo.ExecuteCleanup(t.Context(), CleanupOptions{NoPull: true, Force: true})
+	if err != nil {
+		t.Fatalf("ExecuteCleanup() error = %v", err)
+	}
+
+	if want := []string{"develop", "release/1.0"}; !reflect.DeepEqual(result.ProtectedBranches, want) {
+		t.Errorf("ProtectedBranches = %v, want %v", result.ProtectedBranches, want)
+	}
+	if want := []string{"main"}; !reflect.DeepEqual(result.SkippedBranches, want) {
+		t.Errorf("SkippedBranches = %v, want %v", result.SkippedBranches, want)
+	}
+	if want := []string{"feature"}; !reflect.DeepEqual(result.DeletedBranches, want) {
+		t.Errorf("DeletedBranches = %v, want %v", result.DeletedBranches, want)
+	}
+}
+
+func TestApplyCleanupPlan_ProtectedAfterPlan(t *testing.T) {
+	t.Parallel()
+
+	dir, cleanup := createTestGitRepo(t)
+	defer cleanup()
+	repo := NewRepository(dir)
+
+	runGit(t, dir, "branch", "staging")
+
+	plan, err := repo.PlanCleanup(t.Context(), CleanupOptions{NoPull: true})
+	if err != nil {
+		t.Fatalf("PlanCleanup() error = %v", err)
+	}
+
+	// 計画作成後に保護設定を追加しても削除されないこと
+	runGit(t, dir, "config", "--add", "gitc.protect", "staging")
+
+	result, err := repo.ApplyCleanupPlan(t.Context(), plan, CleanupOptions{})
+	if err != nil {
+		t.Fatalf("ApplyCleanupPlan() error = %v", err)
+	}
+	if len(result.DeletedBranches) != 0 {
+		t.Errorf("DeletedBranches = %v, want none", result.DeletedBranches)
+	}
+	if want := []string{"staging"}; !reflect.DeepEqual(result.ProtectedBranches, want) {
+		t.Errorf("ProtectedBranches = %v, want %v", result.ProtectedBranches, want)
+	}
+}
@@ -0,0 +1,142 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// PullStrategy はデフォルトブランチをプルする方法を表します
+type PullStrategy string
+
+const (
+	PullFFOnly PullStrategy = "ff-only" // fast-forwardできる場合のみ更新する（デフォルト）
+	PullRebase PullStrategy = "rebase"  // ローカルのコミットを上流ブランチの上にリベースする
+	PullMerge  PullStrategy = "merge"   // 上流ブランチをマージする（fast-forwardできる場合はfast-forward）
+	PullNone   PullStrategy = "none"    // プルしない
+)
+
+// ParsePullStrategy は文字列をPullStrategyに変換します（空の場合はPullFFOnly）
+// 以前の真偽値の設定（pull = true / false）との互換性のため、true は ff-only、false は none として扱います
+func ParsePullStrategy(s string) (PullStrategy, error) {
+	switch strategy := PullStrategy(s); strategy {
+	case "", "true":
+		return PullFFOnly, nil
+	case "false":
+		return PullNone, nil
+	case PullFFOnly, PullRebase, PullMerge, PullNone:
+		return strategy, nil
+	default:
+		return "", fmt.Errorf("invalid value %q (must be ff-only, rebase, merge or none)", s)
+	}
+}
+
+// pullArgs はプルの方法に対応する git pull の引数を返します
+// ユーザーの pull.rebase や pull.ff の設定に左右されないよう、方法を明示的に指定します
+func pullArgs(strategy PullStrategy) []string {
+	switch strategy {
+	case PullRebase:
+		return []string{"pull", "--rebase"}
+	case PullMerge:
+		return []string{"pull", "--no-rebase", "--ff", "--no-edit"}
+	default:
+		return []string{"pull", "--no-rebase", "--ff-only"}
+	}
+}
+
+// Pull は現在のブランチを指定した方法で上流ブランチからプルします
+// 失敗した場合は途中のリベース・マージを中止して作業ツリーをプル前の状態に戻し、
+// コンフリクトの場合は ErrMergeConflict、上流ブランチと分岐していてfast-forwardできない場合は ErrDiverged を返します
+func (r *Repository) Pull(ctx context.Context, strategy PullStrategy) error {
+	if strategy == PullNone {
+		return nil
+	}
+
+	if _, err := r.ExecuteCommand(ctx, pullArgs(strategy)...); err != nil {
+		return r.recoverPull(ctx, strategy, err)
+	}
+	return nil
+}
+
+// recoverPull はプルの失敗原因を終了ステータス後のリポジトリの状態から判定し、途中の操作を中止します
+// 中断された場合でもリポジトリを途中の状態で残さないよう、中止処理はキャンセルされないコンテキストで実行します
+func (r *Repository) recoverPull(ctx context.Context, strategy PullStrategy, pullErr error) error {
+	cleanupCtx := context.WithoutCancel(ctx)
+
+	conflicts, err := r.UnmergedPaths(cleanupCtx)
+	if err != nil {
+		return NewGitError("pull", pullErr)
+	}
+	operation, err := r.pullInProgress(cleanupCtx)
+	if err != nil {
+		return NewGitError("pull", pullErr)
+	}
+
+	if operation != "" {
+		if _, err := r.ExecuteCommand(cleanupCtx, operation, "--abort"); err != nil {
+			return NewGitError("pull", pullErr).WithMessage(fmt.Sprintf("failed to abort the %s (%v); resolve it manually", operation, err))
+		}
+	}
+	if IsCanceled(pullErr) {
+		return NewGitError("pull", pullErr)
+	}
+
+	switch {
+	case len(conflicts) > 0:
+		return NewGitError("pull", ErrMergeConflict).WithMessage(fmt.Sprintf("conflicts in %s; the %s was aborted", strings.Join(conflicts, ", "), operation))
+	case operation != "":
+		return NewGitError("pull", pullErr).WithMessage(fmt.Sprintf("the %s was aborted", operation))
+	}
+
+	// fast-forwardできない場合は、上流ブランチとの先行・遅行コミット数で分岐を判定する
+	if strategy == PullFFOnly {
+		ahead, behind, err := r.CountAheadBehind(ctx, "@{upstream}", "HEAD")
+		if err == nil && ahead > 0 && behind > 0 {
+			return NewGitError("pull", ErrDiverged).WithMessage(fmt.Sprintf("%d commit(s) ahead and %d behind the upstream branch; use --pull=rebase or --pull=merge", ahead, behind))
+		}
+	}
+	return NewGitError("pull", pullErr)
+}
+
+// UnmergedPaths は git status でコンフリクトが解決されていないファイルの一覧を返します
+func (r *Repository) UnmergedPaths(ctx context.Context) ([]string, error) {
+	result, err := r.ExecuteCommand(ctx, "status", "--porcelain=v2")
+	if err != nil {
+		return nil, NewGitError("status", err)
+	}
+
+	var paths []string
+	for _, line := range strings.Split(result.Output, "\n") {
+		// u <XY> <sub> <m1> <m2> <m3> <mW> <h1> <h2> <h3> <path>
+		if !strings.HasPrefix(line, "u ") {
+			continue
+		}
+		if fields := strings.SplitN(line, " ", 11); len(fields) == 11 {
+			paths = append(paths, fields[10])
+		}
+	}
+	return paths, nil
+}
+
+// pullInProgress はプルによって途中で止まっているリベースまたはマージを返します（ない場合は空）
+func (r *Repository) pullInProgress(ctx context.Context) (string, error) {
+	info, err := r.DetectRepository(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	for _, state := range []struct{ name, operation string }{
+		{name: "rebase-merge", operation: "rebase"},
+		{name: "rebase-apply", operation: "rebase"},
+		{name: "MERGE_HEAD", operation: "merge"},
+	} {
+		if _, err := os.Stat(filepath.Join(info.GitDir, state.name)); err == nil {
+			return state.operation, nil
+		} else if !os.IsNotExist(err) {
+			return "", NewGitError("pull", err).WithPath(state.name)
+		}
+	}
+	return "", nil
+}
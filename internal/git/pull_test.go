@@ -0,0 +1,338 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParsePullStrategy(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    PullStrategy
+		wantErr bool
+	}{
+		{name: "未指定の場合はff-only", input: "", want: PullFFOnly},
+		{name: "ff-only", input: "ff-only", want: PullFFOnly},
+		{name: "rebase", input: "rebase", want: PullRebase},
+		{name: "merge", input: "merge", want: PullMerge},
+		{name: "none", input: "none", want: PullNone},
+		{name: "以前の真偽値（true）", input: "true", want: PullFFOnly},
+		{name: "以前の真偽値（false）", input: "false", want: PullNone},
+		{name: "不正な値", input: "squash", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParsePullStrategy(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParsePullStrategy() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParsePullStrategy() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// setupPull はoriginに別のクローンからコミットをプッシュし、ローカルのmainをoriginより遅れた状態にします
+// localFileを指定した場合はローカルにもそのファイルを変更するコミットを作成します（test.txtの場合はコンフリクト）
+func setupPull(t *testing.T, localFile string) string {
+	t.Helper()
+
+	dir, cleanup := createTestGitRepo(t)
+	t.Cleanup(cleanup)
+	remote := addTestRemote(t, dir)
+
+	other := filepath.Join(t.TempDir(), "other")
+	runGit(t, dir, "clone", remote, other)
+	if err := os.WriteFile(filepath.Join(other, "test.txt"), []byte("remote change"), 0644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+	runGit(t, other, "-c", "user.name=Other", "-c", "user.email=other@example.com", "commit", "-am", "remote change")
+	runGit(t, other, "push", "origin", "main")
+
+	if localFile != "" {
+		if err := os.WriteFile(filepath.Join(dir, localFile), []byte("local change"), 0644); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+		runGit(t, dir, "add", localFile)
+		runGit(t, dir, "commit", "-m", "local change")
+	}
+	return dir
+}
+
+func TestPull(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		strategy     PullStrategy
+		localFile    string
+		wantUpdated  bool
+		wantConflict bool
+		wantDiverged bool
+	}{
+		{name: "ff-only: fast-forward", strategy: PullFFOnly, wantUpdated: true},
+		{name: "ff-only: 分岐している場合は更新しない", strategy: PullFFOnly, localFile: "local.txt", wantDiverged: true},
+		{name: "rebase: ローカルのコミットをリベース", strategy: PullRebase, localFile: "local.txt", wantUpdated: true},
+		{name: "rebase: コンフリクトした場合は中止", strategy: PullRebase, localFile: "test.txt", wantConflict: true},
+		{name: "merge: 上流ブランチをマージ", strategy: PullMerge, localFile: "local.txt", wantUpdated: true},
+		{name: "merge: コンフリクトした場合は中止", strategy: PullMerge, localFile: "test.txt", wantConflict: true},
+		{name: "none: 何もしない", strategy: PullNone},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			dir := setupPull(t, tt.localFile)
+			before := runGit(t, dir, "rev-parse", "HEAD")
+			repo := NewRepository(dir)
+
+			err := repo.Pull(t.Context(), tt.strategy)
+			if (err != nil) != (tt.wantConflict || tt.wantDiverged) {
+				t.Fatalf("Pull() error = %v", err)
+			}
+			if IsMergeConflict(err) != tt.wantConflict {
+				t.Errorf("IsMergeConflict() = %v, want %v (error = %v)", IsMergeConflict(err), tt.wantConflict, err)
+			}
+			if IsDiverged(err) != tt.wantDiverged {
+				t.Errorf("IsDiverged() = %v, want %v (error = %v)", IsDiverged(err), tt.wantDiverged, err)
+			}
+
+			// 失敗した場合もリベース・マージの途中で残さず、プル前の状態に戻っている
+			if err := repo.CheckInProgress(t.Context()); err != nil {
+				t.Errorf("CheckInProgress() error = %v", err)
+			}
+			if dirty, _ := repo.IsDirty(t.Context()); dirty {
+				t.Error("working tree should be clean")
+			}
+			after := runGit(t, dir, "rev-parse", "HEAD")
+			if (after != before) != tt.wantUpdated {
+				t.Errorf("HEAD updated = %v, want %v", after != before, tt.wantUpdated)
+			}
+			if tt.wantUpdated {
+				runGit(t, dir, "merge-base", "--is-ancestor", "origin/main", "HEAD")
+			}
+		})
+	}
+}
+
+func TestCleanup_PullConflict(t *testing.T) {
+	t.Parallel()
+
+	dir := setupPull(t, "test.txt")
+	// マージ済みの判定はフェッチ済みのorigin/mainに対して行うため、リモートにあるコミットからブランチを作成する
+	runGit(t, dir, "branch", "merged", "HEAD~1")
+
+	result, err := NewRepository(dir).ExecuteCleanup(t.Context(), CleanupOptions{Pull: string(PullRebase)})
+	if err != nil {
+		t.Fatalf("ExecuteCleanup() error = %v", err)
+	}
+	if result.Pulled {
+		t.Error("Pulled should be false when the pull conflicts")
+	}
+	if len(result.Errors) != 1 || !IsMergeConflict(result.Errors[0]) {
+		t.Errorf("Errors = %v, want a merge conflict", result.Errors)
+	}
+	// プルに失敗してもブランチの削除は続行する
+	if len(result.DeletedBranches) != 1 || result.DeletedBranches[0] != "merged" {
+		t.Errorf("DeletedBranches = %v, want [merged]", result.DeletedBranches)
+	}
+}
+
+func TestCleanup_PullBehind(t *testing.T) {
+	t.Parallel()
+
+	dir, cleanup := createTestGitRepo(t)
+	defer cleanup()
+	remote := addTestRemote(t, dir)
+	runGit(t, dir, "checkout", "-b", "feature")
+	runGit(t, dir, "commit", "--allow-empty", "-m", "feature work")
+	runGit(t, dir, "push", "-u", "origin", "feature")
+	runGit(t, dir, "checkout", "main")
+
+	// リモートでfeatureをマージしてブランチを削除する（ローカルのmainは古いまま）
+	other := filepath.Join(t.TempDir(), "other")
+	runGit(t, dir, "clone", remote, other)
+	runGit(t, other, "-c", "user.name=Other", "-c", "user.email=other@example.com", "merge", "--no-ff", "-m", "merge feature", "origin/feature")
+	runGit(t, other, "push", "origin", "main")
+	runGit(t, other, "push", "origin", "--delete", "feature")
+	merged := runGit(t, other, "rev-parse", "main")
+
+	result, err := NewRepository(dir).ExecuteCleanup(t.Context(), CleanupOptions{})
+	if err != nil {
+		t.Fatalf("ExecuteCleanup() error = %v", err)
+	}
+	if len(result.Errors) != 0 {
+		t.Errorf("Errors = %v, want none", result.Errors)
+	}
+	if !result.Pulled {
+		t.Error("Pulled = false, want true")
+	}
+	if result.MergeTarget != "origin/main" {
+		t.Errorf("MergeTarget = %s, want origin/main", result.MergeTarget)
+	}
+	// プル前のローカルのmainではなく、フェッチ済みのorigin/mainに対してマージ済みと判定して削除する
+	if len(result.DeletedBranches) != 1 || result.DeletedBranches[0] != "feature" {
+		t.Errorf("DeletedBranches = %v, want [feature]", result.DeletedBranches)
+	}
+	if got := runGit(t, dir, "rev-parse", "main"); got != merged {
+		t.Errorf("main = %s, want %s", got, merged)
+	}
+}
+
+func TestCleanup_PullWithoutUpstream(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		setup func(t *testing.T, dir string)
+	}{
+		{
+			name:  "リモートがない場合",
+			setup: func(t *testing.T, dir string) {},
+		},
+		{
+			name: "デフォルトブランチに上流ブランチがない場合",
+			setup: func(t *testing.T, dir string) {
+				addTestRemote(t, dir)
+				runGit(t, dir, "branch", "--unset-upstream", "main")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			dir, cleanup := createTestGitRepo(t)
+			defer cleanup()
+			tt.setup(t, dir)
+			runGit(t, dir, "branch", "merged")
+			repo := NewRepository(dir)
+
+			// プルできないことが分かっているため、警告を出さずにプルを省略する
+			plan, err := repo.PlanCleanup(t.Context(), CleanupOptions{Pull: string(PullFFOnly)})
+			if err != nil {
+				t.Fatalf("PlanCleanup() error = %v", err)
+			}
+			if plan.Pull {
+				t.Error("Pull = true, want false")
+			}
+
+			result, err := repo.ApplyCleanupPlan(t.Context(), plan, CleanupOptions{})
+			if err != nil {
+				t.Fatalf("ApplyCleanupPlan() error = %v", err)
+			}
+			if len(result.Errors) != 0 {
+				t.Errorf("Errors = %v, want none", result.Errors)
+			}
+			if result.Pulled {
+				t.Error("Pulled = true, want false")
+			}
+			if len(result.DeletedBranches) != 1 || result.DeletedBranches[0] != "merged" {
+				t.Errorf("DeletedBranches = %v, want [merged]", result.DeletedBranches)
+			}
+		})
+	}
+}
+
+func TestCleanup_MergedModel(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		options      CleanupOptions
+		fromWorktree bool
+		wantDeleted  []string
+		wantKept     string // 削除されずに残るブランチ（空の場合はなし）
+		wantReason   string
+	}{
+		{
+			name:        "プルする場合はローカルとリモートのどちらかにマージ済みのブランチを削除",
+			options:     CleanupOptions{Pull: string(PullMerge)},
+			wantDeleted: []string{"local", "remote"},
+		},
+		{
+			name:        "プルしない場合はローカルにマージ済みのブランチのみ削除",
+			options:     CleanupOptions{NoPull: true},
+			wantDeleted: []string{"local"},
+			wantKept:    "remote",
+			wantReason:  "not merged into main",
+		},
+		{
+			name:        "プルに失敗した場合はgit branch -dが拒否する",
+			options:     CleanupOptions{Pull: string(PullFFOnly)},
+			wantDeleted: []string{"local"},
+			wantKept:    "remote",
+			wantReason:  "delete failed",
+		},
+		{
+			name:         "プルに失敗した場合は強制削除する前に再確認する",
+			options:      CleanupOptions{Pull: string(PullFFOnly)},
+			fromWorktree: true,
+			wantDeleted:  []string{"local"},
+			wantKept:     "remote",
+			wantReason:   "not merged into main after pull",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// remoteはリモートのmainにのみ、localは未プッシュのローカルのmainにのみマージ済み
+			dir, cleanup := createTestGitRepo(t)
+			defer cleanup()
+			addTestRemote(t, dir)
+			runGit(t, dir, "checkout", "-b", "remote")
+			writeTestFile(t, dir, "remote.txt", "remote work")
+			runGit(t, dir, "add", "remote.txt")
+			runGit(t, dir, "commit", "-m", "remote work")
+			runGit(t, dir, "push", "origin", "remote:main")
+			runGit(t, dir, "checkout", "main")
+			writeTestFile(t, dir, "local.txt", "local work")
+			runGit(t, dir, "add", "local.txt")
+			runGit(t, dir, "commit", "-m", "local work")
+			runGit(t, dir, "branch", "local")
+
+			// デフォルトブランチに切り替えられないため、強制削除となるリンクされたワークツリーから実行する
+			repoDir := dir
+			if tt.fromWorktree {
+				repoDir = filepath.Join(t.TempDir(), "wt")
+				runGit(t, dir, "worktree", "add", "-b", "wt", repoDir)
+			}
+			repo, err := OpenRepository(t.Context(), repoDir)
+			if err != nil {
+				t.Fatalf("OpenRepository() error = %v", err)
+			}
+
+			result, err := repo.ExecuteCleanup(t.Context(), tt.options)
+			if err != nil {
+				t.Fatalf("ExecuteCleanup() error = %v", err)
+			}
+			if strings.Join(result.DeletedBranches, ",") != strings.Join(tt.wantDeleted, ",") {
+				t.Errorf("DeletedBranches = %v, want %v (errors: %v)", result.DeletedBranches, tt.wantDeleted, result.Errors)
+			}
+
+			for _, decision := range result.Decisions {
+				if decision.Branch == "remote" && tt.wantKept == "" && decision.Action != BranchActionDelete {
+					// git branch -d の確認を経ずに削除しないこと
+					t.Errorf("remote Action = %s, want %s", decision.Action, BranchActionDelete)
+				}
+				if decision.Branch == tt.wantKept && !strings.Contains(decision.Reason, tt.wantReason) {
+					t.Errorf("%s Reason = %q, want to contain %q", decision.Branch, decision.Reason, tt.wantReason)
+				}
+			}
+			if tt.wantKept != "" {
+				if exists, _ := repo.BranchExists(t.Context(), tt.wantKept); !exists {
+					t.Errorf("%s should be kept", tt.wantKept)
+				}
+			}
+		})
+	}
+}
@@ -1,50 +1,46 @@
 package git
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"slices"
 	"strings"
 	"time"
 )
 
-// Pull はgit pull操作を実行します
-func Pull() error {
-	result, err := ExecuteCommand("pull")
+// Fetch はリモート参照を更新します
+func (r *Repository) Fetch(ctx context.Context) error {
+	_, err := r.ExecuteCommand(ctx, "fetch", "--all", "--prune")
 	if err != nil {
-		// マージコンフリクトか確認
-		if strings.Contains(result.Error, "conflict") || strings.Contains(result.Output, "conflict") {
-			return NewGitError("pull", ErrMergeConflict)
-		}
-		return NewGitError("pull", err)
+		return NewGitError("fetch", err)
 	}
 	return nil
 }
 
-// PullWithRebase はrebaseを伴うgit pullを実行します
-func PullWithRebase() error {
-	result, err := ExecuteCommand("pull", "--rebase")
-	if err != nil {
-		// マージコンフリクトか確認
-		if strings.Contains(result.Error, "conflict") || strings.Contains(result.Output, "conflict") {
-			return NewGitError("pull", ErrMergeConflict).WithMessage("conflict during rebase")
-		}
-		return NewGitError("pull", err).WithMessage("rebase failed")
+// FetchBranch はチェックアウトせずにローカルブランチを上流ブランチの最新状態に更新します
+// "git fetch <remote> <merge>:refs/heads/<branch>" を実行するため、fast-forwardできない場合は失敗します
+// 上流ブランチが設定されていない場合は origin の同名ブランチを使用します
+func (r *Repository) FetchBranch(ctx context.Context, branch string) error {
+	remote, merge := "origin", "refs/heads/"+branch
+	if values, err := r.getConfigValues(ctx, "branch."+branch+".remote"); err == nil && len(values) > 0 {
+		remote = values[len(values)-1]
+	}
+	if values, err := r.getConfigValues(ctx, "branch."+branch+".merge"); err == nil && len(values) > 0 {
+		merge = values[len(values)-1]
 	}
-	return nil
-}
 
-// Fetch はリモート参照を更新します
-func Fetch() error {
-	_, err := ExecuteCommand("fetch", "--all", "--prune")
-	if err != nil {
-		return NewGitError("fetch", err)
+	refspec := merge + ":refs/heads/" + branch
+	if _, err := r.ExecuteCommand(ctx, "fetch", remote, refspec); err != nil {
+		return NewGitError("fetch", err).WithMessage(fmt.Sprintf("failed to update %s from %s %s", branch, remote, merge))
 	}
 	return nil
 }
 
-// CheckRemoteAccess はリモートリポジトリにアクセスできるか確認します
-func CheckRemoteAccess() error {
+// CheckRemoteAccess は指定したリモートリポジトリにアクセスできるか確認します
+func (r *Repository) CheckRemoteAccess(ctx context.Context, remote string) error {
 	// リモートチェックのタイムアウトを設定
-	result, err := ExecuteCommandWithTimeout(10*time.Second, "ls-remote", "--heads", "origin")
+	result, err := r.ExecuteCommandWithTimeout(ctx, remoteQueryTimeout, "ls-remote", "--heads", remote)
 	if err != nil {
 		return NewGitError("check-remote", ErrRemoteAccessFailed).WithMessage(fmt.Sprintf("failed to access remote: %v", err))
 	}
@@ -52,12 +48,12 @@ func CheckRemoteAccess() error {
 	// 出力がない場合、リモートは空だがアクセス可能かもしれない
 	if result.Output == "" {
 		// リモートが存在するか確認するためURLを取得
-		urlResult, urlErr := ExecuteCommand("remote", "get-url", "origin")
+		urlResult, urlErr := r.ExecuteCommand(ctx, "remote", "get-url", remote)
 		if urlErr != nil {
-			return NewGitError("check-remote", ErrRemoteAccessFailed).WithMessage("no remote 'origin' configured")
+			return NewGitError("check-remote", ErrRemoteAccessFailed).WithMessage(fmt.Sprintf("no remote '%s' configured", remote))
 		}
 		if urlResult.Output == "" {
-			return NewGitError("check-remote", ErrRemoteAccessFailed).WithMessage("remote 'origin' has no URL")
+			return NewGitError("check-remote", ErrRemoteAccessFailed).WithMessage(fmt.Sprintf("remote '%s' has no URL", remote))
 		}
 	}
 	
@@ -65,47 +61,68 @@ func CheckRemoteAccess() error {
 }
 
 // ExecuteCommandWithTimeout はタイムアウト付きでGitコマンドを実行します
-func ExecuteCommandWithTimeout(timeout time.Duration, args ...string) (*CommandResult, error) {
-	type resultWrapper struct {
-		result *CommandResult
-		err    error
-	}
-	
-	done := make(chan resultWrapper, 1)
-	
-	go func() {
-		result, err := ExecuteCommand(args...)
-		done <- resultWrapper{result: result, err: err}
-	}()
-	
-	select {
-	case <-time.After(timeout):
-		return nil, fmt.Errorf("command timed out after %v", timeout)
-	case wrapper := <-done:
-		return wrapper.result, wrapper.err
+// タイムアウトした場合はプロセスを終了させ、context.DeadlineExceededを含むエラーを返します
+func (r *Repository) ExecuteCommandWithTimeout(ctx context.Context, timeout time.Duration, args ...string) (*CommandResult, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	result, err := r.ExecuteCommand(ctx, args...)
+	if err != nil && errors.Is(err, context.DeadlineExceeded) {
+		return result, fmt.Errorf("command timed out after %v: %w", timeout, err)
 	}
+	return result, err
 }
 
 // HasRemote は指定された名前のリモートが存在するか確認します
-func HasRemote(name string) (bool, error) {
-	result, err := ExecuteCommand("remote")
+func (r *Repository) HasRemote(ctx context.Context, name string) (bool, error) {
+	remotes, err := r.ListRemotes(ctx)
 	if err != nil {
-		return false, NewGitError("check-remote", err)
+		return false, err
 	}
-	
-	remotes := strings.Split(result.Output, "\n")
-	for _, remote := range remotes {
-		if strings.TrimSpace(remote) == name {
-			return true, nil
+	return slices.Contains(remotes, name), nil
+}
+
+// ListRemotes は設定されているリモートの一覧を返します
+func (r *Repository) ListRemotes(ctx context.Context) ([]string, error) {
+	result, err := r.ExecuteCommand(ctx, "remote")
+	if err != nil {
+		return nil, NewGitError("check-remote", err)
+	}
+	return filterEmptyStrings(strings.Split(result.Output, "\n")), nil
+}
+
+// PrimaryRemote はデフォルトブランチの検出に使用する主要なリモートを返します
+// checkout.defaultRemote、clone.defaultRemoteName の設定、origin、唯一のリモートの順に決定し、
+// 決定できない場合（リモートがない場合を含む）は空を返します
+func (r *Repository) PrimaryRemote(ctx context.Context) (string, error) {
+	remotes, err := r.ListRemotes(ctx)
+	if err != nil || len(remotes) == 0 {
+		return "", err
+	}
+
+	for _, key := range []string{"checkout.defaultRemote", "clone.defaultRemoteName"} {
+		values, err := r.getConfigValues(ctx, key)
+		if err != nil {
+			return "", NewGitError("check-remote", err).WithPath(key)
+		}
+		if len(values) > 0 && slices.Contains(remotes, values[len(values)-1]) {
+			return values[len(values)-1], nil
 		}
 	}
-	
-	return false, nil
+
+	switch {
+	case slices.Contains(remotes, "origin"):
+		return "origin", nil
+	case len(remotes) == 1:
+		return remotes[0], nil
+	default:
+		return "", nil
+	}
 }
 
 // GetRemoteURL は指定されたリモートのURLを返します
-func GetRemoteURL(name string) (string, error) {
-	result, err := ExecuteCommand("remote", "get-url", name)
+func (r *Repository) GetRemoteURL(ctx context.Context, name string) (string, error) {
+	result, err := r.ExecuteCommand(ctx, "remote", "get-url", name)
 	if err != nil {
 		return "", NewGitError("get-remote-url", err).WithPath(name)
 	}
@@ -0,0 +1,103 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// remoteQueryTimeout はリモートへの問い合わせ（git ls-remote）のタイムアウトです
+const remoteQueryTimeout = 10 * time.Second
+
+// RemoteHEADStatus はリモートのデフォルトブランチ（HEAD）とローカルの参照の状態を表します
+type RemoteHEADStatus struct {
+	Remote string // リモート名
+	Local  string // refs/remotes/<remote>/HEAD が指すブランチ（未設定の場合は空）
+	Actual string // リモートの実際のHEADが指すブランチ（git ls-remote --symref で取得）
+	Fixed  bool   // refs/remotes/<remote>/HEAD をActualに更新したかどうか（Localは更新前の値）
+}
+
+// Missing は refs/remotes/<remote>/HEAD が設定されていないかどうかを返します
+func (s *RemoteHEADStatus) Missing() bool {
+	return s.Local == ""
+}
+
+// Stale は refs/remotes/<remote>/HEAD がリモートの実際のHEADと異なるかどうかを返します
+func (s *RemoteHEADStatus) Stale() bool {
+	return s.Local != "" && s.Actual != "" && s.Local != s.Actual
+}
+
+// QueryRemoteHEAD はリモートに問い合わせ、リモートのHEADが指すブランチ名を返します
+func (r *Repository) QueryRemoteHEAD(ctx context.Context, remote string) (string, error) {
+	result, err := r.ExecuteCommandWithTimeout(ctx, remoteQueryTimeout, "ls-remote", "--symref", remote, "HEAD")
+	if err != nil {
+		return "", NewGitError("ls-remote", err).WithPath(remote)
+	}
+
+	branch := parseSymrefHEAD(result.Output)
+	if branch == "" {
+		return "", NewGitError("ls-remote", fmt.Errorf("remote HEAD is not a branch")).WithPath(remote)
+	}
+	return branch, nil
+}
+
+// parseSymrefHEAD は git ls-remote --symref <remote> HEAD の出力からHEADが指すブランチ名を取り出します
+// 出力例: "ref: refs/heads/main\tHEAD\n<sha>\tHEAD"
+func parseSymrefHEAD(output string) string {
+	for _, line := range strings.Split(output, "\n") {
+		symref, ok := strings.CutPrefix(line, "ref: ")
+		if !ok {
+			continue
+		}
+		if target, name, _ := strings.Cut(symref, "\t"); name == "HEAD" {
+			return strings.TrimPrefix(target, "refs/heads/")
+		}
+	}
+	return ""
+}
+
+// SetRemoteHEAD は refs/remotes/<remote>/HEAD を指定したブランチに設定します
+// refs/remotes/<remote>/<branch> が存在しない場合（まだフェッチしていない場合）は失敗します
+func (r *Repository) SetRemoteHEAD(ctx context.Context, remote, branch string) error {
+	if _, err := r.ExecuteCommand(ctx, "remote", "set-head", remote, branch); err != nil {
+		return NewGitError("remote-set-head", err).WithPath(remote)
+	}
+	return nil
+}
+
+// CheckRemoteHEAD は refs/remotes/<remote>/HEAD とリモートの実際のHEADを比較します
+func (r *Repository) CheckRemoteHEAD(ctx context.Context, remote string) (*RemoteHEADStatus, error) {
+	status := &RemoteHEADStatus{Remote: remote}
+	status.Local, _ = r.remoteHEAD(ctx, remote)
+
+	actual, err := r.QueryRemoteHEAD(ctx, remote)
+	if err != nil {
+		return status, err
+	}
+	status.Actual = actual
+	return status, nil
+}
+
+// FixRemoteHEAD は refs/remotes/<remote>/HEAD が設定されていないか古い場合に、リモートの実際のHEADに合わせて更新します
+func (r *Repository) FixRemoteHEAD(ctx context.Context, remote string) (*RemoteHEADStatus, error) {
+	status, err := r.CheckRemoteHEAD(ctx, remote)
+	if err != nil {
+		return status, err
+	}
+	if !status.Missing() && !status.Stale() {
+		return status, nil
+	}
+
+	if err := r.SetRemoteHEAD(ctx, remote, status.Actual); err != nil {
+		return status, err
+	}
+	status.Fixed = true
+	return status, nil
+}
+
+// staleRemoteHEADWarning は refs/remotes/<remote>/HEAD がリモートの実際のHEADと異なる場合の警告を返します
+func staleRemoteHEADWarning(status *RemoteHEADStatus) string {
+	return fmt.Sprintf("refs/remotes/%s/HEAD points to %s, but the default branch of %s is %s; run \"gitc migrate-default\" if it was renamed, or \"gitc doctor --fix-head\" to update it",
+		status.Remote, status.Local, status.Remote, status.Actual)
+}
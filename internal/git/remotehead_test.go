@@ -0,0 +1,141 @@
+package git
+
+import (
+	"testing"
+)
+
+func TestParseSymrefHEAD(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   string
+	}{
+		{
+			name:   "ブランチを指すHEAD",
+			output: "ref: refs/heads/main\tHEAD\n0123456789abcdef\tHEAD",
+			want:   "main",
+		},
+		{
+			name:   "スラッシュを含むブランチ名",
+			output: "ref: refs/heads/release/main\tHEAD\n0123456789abcdef\tHEAD",
+			want:   "release/main",
+		},
+		{
+			name:   "HEADがシンボリック参照でない場合",
+			output: "0123456789abcdef\tHEAD",
+			want:   "",
+		},
+		{
+			name:   "出力なし",
+			output: "",
+			want:   "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseSymrefHEAD(tt.output); got != tt.want {
+				t.Errorf("parseSymrefHEAD() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFixRemoteHEAD(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		setup     func(t *testing.T, dir string)
+		wantLocal string // 修復前の refs/remotes/origin/HEAD
+		wantFixed bool
+	}{
+		{
+			name:      "正しく設定されている場合は変更しない",
+			setup:     func(t *testing.T, dir string) {},
+			wantLocal: "main",
+			wantFixed: false,
+		},
+		{
+			name: "設定されていない場合はリモートのHEADに合わせる",
+			setup: func(t *testing.T, dir string) {
+				runGit(t, dir, "remote", "set-head", "origin", "--delete")
+			},
+			wantLocal: "",
+			wantFixed: true,
+		},
+		{
+			name: "古いブランチを指している場合はリモートのHEADに合わせる",
+			setup: func(t *testing.T, dir string) {
+				runGit(t, dir, "push", "origin", "main:master")
+				runGit(t, dir, "remote", "set-head", "origin", "master")
+			},
+			wantLocal: "master",
+			wantFixed: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			dir, cleanup := createTestGitRepo(t)
+			defer cleanup()
+			addTestRemote(t, dir)
+			tt.setup(t, dir)
+			repo := NewRepository(dir)
+
+			before, err := repo.CheckRemoteHEAD(t.Context(), "origin")
+			if err != nil {
+				t.Fatalf("CheckRemoteHEAD() error = %v", err)
+			}
+			if before.Local != tt.wantLocal || before.Actual != "main" {
+				t.Errorf("CheckRemoteHEAD() = %+v, want local %q and actual main", before, tt.wantLocal)
+			}
+
+			status, err := repo.FixRemoteHEAD(t.Context(), "origin")
+			if err != nil {
+				t.Fatalf("FixRemoteHEAD() error = %v", err)
+			}
+			if status.Fixed != tt.wantFixed {
+				t.Errorf("Fixed = %v, want %v", status.Fixed, tt.wantFixed)
+			}
+			if got := runGit(t, dir, "symbolic-ref", "refs/remotes/origin/HEAD"); got != "refs/remotes/origin/main" {
+				t.Errorf("refs/remotes/origin/HEAD = %s, want refs/remotes/origin/main", got)
+			}
+		})
+	}
+}
+
+func TestPlanCleanupStaleRemoteHEAD(t *testing.T) {
+	t.Parallel()
+
+	dir, cleanup := createTestGitRepo(t)
+	defer cleanup()
+	addTestRemote(t, dir)
+
+	// ローカルのorigin/HEADだけが古いブランチを指している状態
+	runGit(t, dir, "branch", "master")
+	runGit(t, dir, "push", "origin", "master")
+	runGit(t, dir, "remote", "set-head", "origin", "master")
+
+	plan, err := NewRepository(dir).PlanCleanup(t.Context(), CleanupOptions{NoPull: true})
+	if err != nil {
+		t.Fatalf("PlanCleanup() error = %v", err)
+	}
+
+	found := false
+	for _, warning := range plan.Warnings {
+		if contains(warning, "gitc doctor --fix-head") {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("Warnings = %v, want stale origin/HEAD warning", plan.Warnings)
+	}
+
+	// 警告のみで、origin/HEADは自動では変更しない
+	if got := runGit(t, dir, "symbolic-ref", "refs/remotes/origin/HEAD"); got != "refs/remotes/origin/master" {
+		t.Errorf("refs/remotes/origin/HEAD = %s, want refs/remotes/origin/master", got)
+	}
+}
@@ -1,27 +1,111 @@
 package git
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 )
 
-// IsGitRepository は指定されたディレクトリがGitリポジトリかどうかを確認します
-func IsGitRepository(path string) error {
-	gitDir := filepath.Join(path, ".git")
-	info, err := os.Stat(gitDir)
+// Repository は操作対象のGitリポジトリを表します
+// すべてのGit操作はDirを作業ディレクトリとして実行されるため、
+// プロセスのカレントディレクトリに依存せず複数のリポジトリを並行して操作できます
+type Repository struct {
+	Dir    string   // Gitコマンドを実行するディレクトリ
+	GitDir string   // GIT_DIRとして渡すディレクトリ（空の場合はDirから自動検出）
+	Env    []string // Gitコマンドに追加で渡す環境変数（"KEY=VALUE"形式）
+	Runner Runner   // Gitコマンドの実行方法（nilの場合はgitの実行ファイルを起動）
+
+	Info *RepositoryInfo // OpenRepositoryで解決したリポジトリのパス（未解決の場合はnil）
+}
+
+// RepositoryInfo はgit rev-parseで解決したリポジトリのパスを表します
+// リンクされたワークツリーではGitDirはワークツリーごとのディレクトリ、
+// CommonDirはすべてのワークツリーで共有されるディレクトリ（ブランチの実体がある場所）を指します
+type RepositoryInfo struct {
+	TopLevel  string `json:"top_level,omitempty"` // 作業ツリーのルート（作業ツリーがない場合は空）
+	GitDir    string `json:"git_dir"`             // このワークツリーのGitディレクトリ
+	CommonDir string `json:"common_dir"`          // ワークツリー間で共有されるGitディレクトリ
+	Bare      bool   `json:"bare"`                // ベアリポジトリかどうか
+}
+
+// NewRepository は指定されたディレクトリを対象とするRepositoryを作成します
+func NewRepository(dir string) *Repository {
+	return &Repository{Dir: dir}
+}
+
+// OpenRepository は指定されたディレクトリを含むリポジトリを検出し、そのルートを対象とするRepositoryを作成します
+// サブディレクトリ、リンクされたワークツリー、サブモジュール、GIT_DIRが設定された環境でも検出できます
+func OpenRepository(ctx context.Context, dir string) (*Repository, error) {
+	info, err := NewRepository(dir).DetectRepository(ctx)
 	if err != nil {
-		if os.IsNotExist(err) {
-			return fmt.Errorf("not a git repository: %s", path)
-		}
-		return fmt.Errorf("failed to check git directory: %w", err)
+		return nil, err
+	}
+
+	// 作業ツリーがない場合（ベアリポジトリなど）はGitディレクトリで操作する
+	root := info.TopLevel
+	if root == "" {
+		root = info.GitDir
+	}
+	return &Repository{Dir: root, Info: info}, nil
+}
+
+// DetectRepository はgit rev-parseでリポジトリのパスを解決します
+func (r *Repository) DetectRepository(ctx context.Context) (*RepositoryInfo, error) {
+	// --show-toplevelは作業ツリーがない場合に失敗するため最後に指定する
+	// （失敗した場合でもそれまでの値は出力される）
+	// --path-format=absolute はgit 2.31以降でしか使えないため、相対パスは実行ディレクトリを基準に解決する
+	result, err := r.ExecuteCommand(ctx, "rev-parse",
+		"--git-dir", "--git-common-dir", "--is-bare-repository", "--show-toplevel")
+	if result == nil {
+		return nil, NewGitError("detect-repository", err)
+	}
+	if IsCanceled(err) {
+		return nil, NewGitError("detect-repository", err)
+	}
+
+	lines := strings.Split(result.Output, "\n")
+	if len(lines) < 3 || lines[0] == "" {
+		return nil, NewGitError("detect-repository", ErrNotGitRepository).WithPath(r.Dir)
 	}
 
-	if !info.IsDir() {
-		return fmt.Errorf(".git exists but is not a directory: %s", gitDir)
+	info := &RepositoryInfo{Bare: lines[2] == "true"}
+	if err == nil && len(lines) > 3 {
+		info.TopLevel = filepath.Clean(lines[3])
+	}
+	if info.GitDir, err = r.absPath(lines[0]); err != nil {
+		return nil, NewGitError("detect-repository", err)
+	}
+	if info.CommonDir, err = r.absPath(lines[1]); err != nil {
+		return nil, NewGitError("detect-repository", err)
+	}
+	return info, nil
+}
+
+// absPath はgit rev-parseが返したパスを絶対パスにします
+// 相対パスはgitを実行したディレクトリ（Dir、空の場合はカレントディレクトリ）を基準に解決し、
+// gitが返す絶対パスと比較できるようシンボリックリンクも解決します
+func (r *Repository) absPath(path string) (string, error) {
+	if filepath.IsAbs(path) {
+		return filepath.Clean(path), nil
 	}
 
-	return nil
+	base, err := filepath.Abs(r.Dir)
+	if err != nil {
+		return "", err
+	}
+	abs := filepath.Join(base, path)
+	if resolved, err := filepath.EvalSymlinks(abs); err == nil {
+		return resolved, nil
+	}
+	return abs, nil
+}
+
+// IsGitRepository は指定されたディレクトリがGitリポジトリ（またはその内部）かどうかを確認します
+func IsGitRepository(ctx context.Context, path string) error {
+	_, err := NewRepository(path).DetectRepository(ctx)
+	return err
 }
 
 // GetCurrentDirectory は現在の作業ディレクトリを返します
@@ -31,4 +115,16 @@ func GetCurrentDirectory() (string, error) {
 		return "", fmt.Errorf("failed to get current directory: %w", err)
 	}
 	return cwd, nil
+}
+
+// GetTopLevel はリポジトリのルートディレクトリを返します
+func (r *Repository) GetTopLevel(ctx context.Context) (string, error) {
+	if r.Info != nil && r.Info.TopLevel != "" {
+		return r.Info.TopLevel, nil
+	}
+	result, err := r.ExecuteCommand(ctx, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return "", NewGitError("get-toplevel", err)
+	}
+	return result.Output, nil
 }
\ No newline at end of file
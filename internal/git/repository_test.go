@@ -1,8 +1,10 @@
 package git
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
+	"sync"
 	"testing"
 )
 
@@ -16,15 +18,23 @@ func TestIsGitRepository(t *testing.T) {
 		{
 			name: "有効なGitリポジトリ",
 			setup: func(t *testing.T) string {
-				dir := t.TempDir()
-				gitDir := filepath.Join(dir, ".git")
-				if err := os.Mkdir(gitDir, 0755); err != nil {
-					t.Fatalf("Failed to create .git directory: %v", err)
-				}
+				dir, _ := createTestGitRepo(t)
 				return dir
 			},
 			wantErr: false,
 		},
+		{
+			name: "リポジトリのサブディレクトリ",
+			setup: func(t *testing.T) string {
+				dir, _ := createTestGitRepo(t)
+				sub := filepath.Join(dir, "sub", "dir")
+				if err := os.MkdirAll(sub, 0755); err != nil {
+					t.Fatalf("Failed to create subdirectory: %v", err)
+				}
+				return sub
+			},
+			wantErr: false,
+		},
 		{
 			name: "Gitリポジトリではないディレクトリ",
 			setup: func(t *testing.T) string {
@@ -34,7 +44,17 @@ func TestIsGitRepository(t *testing.T) {
 			errMsg:  "not a git repository",
 		},
 		{
-			name: ".gitがファイルの場合",
+			name: ".gitがファイルの場合（リンクされたワークツリー）",
+			setup: func(t *testing.T) string {
+				dir, _ := createTestGitRepo(t)
+				worktree := filepath.Join(t.TempDir(), "wt")
+				runGit(t, dir, "worktree", "add", "-b", "wt", worktree)
+				return worktree
+			},
+			wantErr: false,
+		},
+		{
+			name: ".gitファイルの参照先が存在しない場合",
 			setup: func(t *testing.T) string {
 				dir := t.TempDir()
 				gitFile := filepath.Join(dir, ".git")
@@ -44,14 +64,14 @@ func TestIsGitRepository(t *testing.T) {
 				return dir
 			},
 			wantErr: true,
-			errMsg:  ".git exists but is not a directory",
+			errMsg:  "not a git repository",
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			dir := tt.setup(t)
-			err := IsGitRepository(dir)
+			err := IsGitRepository(t.Context(), dir)
 
 			if (err != nil) != tt.wantErr {
 				t.Errorf("IsGitRepository() error = %v, wantErr %v", err, tt.wantErr)
@@ -67,6 +87,127 @@ func TestIsGitRepository(t *testing.T) {
 	}
 }
 
+func TestOpenRepository(t *testing.T) {
+	t.Parallel()
+
+	dir, _ := createTestGitRepo(t)
+	dir = resolvePath(t, dir)
+	gitDir := filepath.Join(dir, ".git")
+
+	sub := filepath.Join(dir, "sub")
+	if err := os.Mkdir(sub, 0755); err != nil {
+		t.Fatalf("Failed to create subdirectory: %v", err)
+	}
+
+	worktree := filepath.Join(resolvePath(t, t.TempDir()), "wt")
+	runGit(t, dir, "worktree", "add", "-b", "wt", worktree)
+
+	submoduleSrc, _ := createTestGitRepo(t)
+	runGit(t, dir, "-c", "protocol.file.allow=always", "submodule", "add", submoduleSrc, "mod")
+
+	bare := filepath.Join(resolvePath(t, t.TempDir()), "bare.git")
+	runGit(t, dir, "clone", "--bare", "-q", dir, bare)
+
+	tests := []struct {
+		name string
+		dir  string
+		want RepositoryInfo
+	}{
+		{
+			name: "リポジトリのルート",
+			dir:  dir,
+			want: RepositoryInfo{TopLevel: dir, GitDir: gitDir, CommonDir: gitDir},
+		},
+		{
+			name: "サブディレクトリ",
+			dir:  sub,
+			want: RepositoryInfo{TopLevel: dir, GitDir: gitDir, CommonDir: gitDir},
+		},
+		{
+			name: "リンクされたワークツリー",
+			dir:  worktree,
+			want: RepositoryInfo{TopLevel: worktree, GitDir: filepath.Join(gitDir, "worktrees", "wt"), CommonDir: gitDir},
+		},
+		{
+			name: "サブモジュール",
+			dir:  filepath.Join(dir, "mod"),
+			want: RepositoryInfo{
+				TopLevel:  filepath.Join(dir, "mod"),
+				GitDir:    filepath.Join(gitDir, "modules", "mod"),
+				CommonDir: filepath.Join(gitDir, "modules", "mod"),
+			},
+		},
+		{
+			name: "ベアリポジトリ",
+			dir:  bare,
+			want: RepositoryInfo{GitDir: bare, CommonDir: bare, Bare: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, err := OpenRepository(t.Context(), tt.dir)
+			if err != nil {
+				t.Fatalf("OpenRepository() error = %v", err)
+			}
+			if *repo.Info != tt.want {
+				t.Errorf("OpenRepository().Info = %+v, want %+v", *repo.Info, tt.want)
+			}
+
+			wantDir := tt.want.TopLevel
+			if wantDir == "" {
+				wantDir = tt.want.GitDir
+			}
+			if repo.Dir != wantDir {
+				t.Errorf("OpenRepository().Dir = %s, want %s", repo.Dir, wantDir)
+			}
+		})
+	}
+
+	t.Run("GIT_DIRが設定されている場合", func(t *testing.T) {
+		repo := &Repository{Dir: t.TempDir(), GitDir: gitDir}
+		info, err := repo.DetectRepository(t.Context())
+		if err != nil {
+			t.Fatalf("DetectRepository() error = %v", err)
+		}
+		if info.GitDir != gitDir || info.CommonDir != gitDir {
+			t.Errorf("DetectRepository() = %+v, want GitDir and CommonDir %s", info, gitDir)
+		}
+	})
+}
+
+func TestDetectRepository_RelativePaths(t *testing.T) {
+	t.Parallel()
+
+	// --path-format=absolute を使わないため、リポジトリのルートではgitは相対パスを返す
+	fake := newFakeRunner(t)
+	repo := fake.repository()
+	dir := resolvePath(t, repo.Dir)
+	fake.expect("rev-parse", "--git-dir", "--git-common-dir", "--is-bare-repository", "--show-toplevel").
+		returns(".git\n.git\nfalse\n" + dir)
+
+	info, err := repo.DetectRepository(t.Context())
+	if err != nil {
+		t.Fatalf("DetectRepository() error = %v", err)
+	}
+	gitDir := filepath.Join(dir, ".git")
+	if want := (RepositoryInfo{TopLevel: dir, GitDir: gitDir, CommonDir: gitDir}); *info != want {
+		t.Errorf("DetectRepository() = %+v, want %+v", *info, want)
+	}
+}
+
+// resolvePath はシンボリックリンクを解決したパスを返すヘルパー関数
+// （macOSの/var -> /private/varのように、gitが返すパスと一致させるため）
+func resolvePath(t *testing.T, path string) string {
+	t.Helper()
+
+	resolved, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		t.Fatalf("Failed to resolve path %s: %v", path, err)
+	}
+	return resolved
+}
+
 func TestGetCurrentDirectory(t *testing.T) {
 	// 現在のディレクトリを取得
 	got, err := GetCurrentDirectory()
@@ -90,4 +231,67 @@ func TestGetCurrentDirectory(t *testing.T) {
 func contains(str, substr string) bool {
 	return len(str) >= len(substr) && str[:len(substr)] == substr || 
 		len(str) >= len(substr) && contains(str[1:], substr)
-}
\ No newline at end of file
+}
+
+func TestRepository_ConcurrentOperations(t *testing.T) {
+	t.Parallel()
+
+	// 異なるブランチ構成のリポジトリを並行して操作できること
+	dirs := make([]string, 4)
+	for i := range dirs {
+		dir, _ := createTestGitRepo(t)
+		for j := 0; j <= i; j++ {
+			runGit(t, dir, "branch", fmt.Sprintf("feature-%d", j))
+		}
+		dirs[i] = dir
+	}
+
+	var wg sync.WaitGroup
+	errs := make([]error, len(dirs))
+	deleted := make([]int, len(dirs))
+	for i, dir := range dirs {
+		wg.Add(1)
+		go func(i int, repo *Repository) {
+			defer wg.Done()
+			result, err := repo.ExecuteCleanup(t.Context(), CleanupOptions{NoPull: true})
+			if err != nil {
+				errs[i] = err
+				return
+			}
+			deleted[i] = len(result.DeletedBranches)
+		}(i, NewRepository(dir))
+	}
+	wg.Wait()
+
+	for i := range dirs {
+		if errs[i] != nil {
+			t.Errorf("repo %d: ExecuteCleanup() error = %v", i, errs[i])
+			continue
+		}
+		if deleted[i] != i+1 {
+			t.Errorf("repo %d: deleted %d branches, want %d", i, deleted[i], i+1)
+		}
+	}
+}
+
+func TestRepository_GitDirAndEnv(t *testing.T) {
+	t.Parallel()
+
+	dir, _ := createTestGitRepo(t)
+	runGit(t, dir, "checkout", "-b", "feature")
+
+	// 作業ディレクトリがリポジトリ外でもGIT_DIRで対象を指定できること
+	repo := &Repository{
+		Dir:    t.TempDir(),
+		GitDir: filepath.Join(dir, ".git"),
+		Env:    []string{"GIT_CONFIG_NOSYSTEM=1"},
+	}
+
+	branch, err := repo.GetCurrentBranch(t.Context())
+	if err != nil {
+		t.Fatalf("GetCurrentBranch() error = %v", err)
+	}
+	if branch != "feature" {
+		t.Errorf("GetCurrentBranch() = %s, want feature", branch)
+	}
+}
@@ -0,0 +1,95 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// DirtyPolicy は作業ツリーに未コミットの変更がある場合の扱いを表します
+type DirtyPolicy string
+
+const (
+	DirtyAbort DirtyPolicy = "abort" // 何もせずに終了する（デフォルト）
+	DirtyStash DirtyPolicy = "stash" // 変更を一時退避し、元のブランチに戻って再適用する
+	DirtyKeep  DirtyPolicy = "keep"  // 変更をそのままデフォルトブランチに持ち越す
+)
+
+// autostashPrefix はgitcが作成するスタッシュのメッセージの接頭辞です
+const autostashPrefix = "gitc autostash"
+
+// ParseDirtyPolicy は文字列をDirtyPolicyに変換します（空の場合はDirtyAbort）
+func ParseDirtyPolicy(s string) (DirtyPolicy, error) {
+	switch policy := DirtyPolicy(s); policy {
+	case "":
+		return DirtyAbort, nil
+	case DirtyAbort, DirtyStash, DirtyKeep:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("invalid value %q (must be abort, stash or keep)", s)
+	}
+}
+
+// IsDirty は追跡対象のファイルに未コミットの変更（ステージ済みを含む）があるか確認します
+// 追跡されていないファイルはブランチを切り替えても影響を受けないため対象外です
+func (r *Repository) IsDirty(ctx context.Context) (bool, error) {
+	result, err := r.ExecuteCommand(ctx, "status", "--porcelain=v2")
+	if err != nil {
+		return false, NewGitError("status", err)
+	}
+
+	for _, line := range strings.Split(result.Output, "\n") {
+		// 1: 通常の変更、2: 名前の変更・コピー、u: 未解決のコンフリクト
+		// ?: 追跡されていないファイル、!: 無視されたファイル
+		switch {
+		case strings.HasPrefix(line, "1 "), strings.HasPrefix(line, "2 "), strings.HasPrefix(line, "u "):
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Stash は未コミットの変更を "gitc autostash <timestamp>" という名前でスタッシュし、そのコミットを返します
+func (r *Repository) Stash(ctx context.Context, now time.Time) (string, error) {
+	message := fmt.Sprintf("%s %s", autostashPrefix, now.UTC().Format(time.RFC3339))
+	if _, err := r.ExecuteCommand(ctx, "stash", "push", "-m", message); err != nil {
+		return "", NewGitError("stash", err)
+	}
+
+	result, err := r.ExecuteCommand(ctx, "rev-parse", "--verify", "refs/stash")
+	if err != nil {
+		return "", NewGitError("stash", err).WithMessage("failed to resolve stash")
+	}
+	return result.Output, nil
+}
+
+// StashRef は指定したコミットのスタッシュの参照名（例: stash@{0}）を返します
+// 他のスタッシュが追加されても正しいエントリを指すよう、コミットで検索します
+func (r *Repository) StashRef(ctx context.Context, sha string) (string, error) {
+	result, err := r.ExecuteCommand(ctx, "stash", "list", "--format=%gd %H")
+	if err != nil {
+		return "", NewGitError("stash", err)
+	}
+
+	for _, line := range strings.Split(result.Output, "\n") {
+		ref, hash, found := strings.Cut(line, " ")
+		if found && hash == sha {
+			return ref, nil
+		}
+	}
+	return "", NewGitError("stash", fmt.Errorf("stash %s not found", shortSHA(sha)))
+}
+
+// PopStash は指定したコミットのスタッシュを現在のブランチに適用し、スタッシュから削除します
+// コンフリクトなどで適用できない場合、スタッシュは残ります
+func (r *Repository) PopStash(ctx context.Context, sha string) error {
+	ref, err := r.StashRef(ctx, sha)
+	if err != nil {
+		return err
+	}
+	if _, err := r.ExecuteCommand(ctx, "stash", "pop", "--index", ref); err != nil {
+		return NewGitError("stash", err).WithMessage(fmt.Sprintf("failed to re-apply %s", ref))
+	}
+	return nil
+}
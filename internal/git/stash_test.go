@@ -0,0 +1,194 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseDirtyPolicy(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    DirtyPolicy
+		wantErr bool
+	}{
+		{name: "未指定の場合はabort", input: "", want: DirtyAbort},
+		{name: "abort", input: "abort", want: DirtyAbort},
+		{name: "stash", input: "stash", want: DirtyStash},
+		{name: "keep", input: "keep", want: DirtyKeep},
+		{name: "不正な値", input: "discard", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseDirtyPolicy(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseDirtyPolicy() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseDirtyPolicy() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsDirty(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		setup func(t *testing.T, dir string)
+		want  bool
+	}{
+		{
+			name:  "変更なし",
+			setup: func(t *testing.T, dir string) {},
+			want:  false,
+		},
+		{
+			name: "追跡対象ファイルの変更",
+			setup: func(t *testing.T, dir string) {
+				writeTestFile(t, dir, "test.txt", "modified")
+			},
+			want: true,
+		},
+		{
+			name: "ステージ済みの新規ファイル",
+			setup: func(t *testing.T, dir string) {
+				writeTestFile(t, dir, "new.txt", "new")
+				runGit(t, dir, "add", "new.txt")
+			},
+			want: true,
+		},
+		{
+			name: "追跡されていないファイルのみ",
+			setup: func(t *testing.T, dir string) {
+				writeTestFile(t, dir, "untracked.txt", "untracked")
+			},
+			want: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			dir, cleanup := createTestGitRepo(t)
+			defer cleanup()
+			tt.setup(t, dir)
+
+			got, err := NewRepository(dir).IsDirty(t.Context())
+			if err != nil {
+				t.Fatalf("IsDirty() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("IsDirty() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCleanup_DirtyWorktree(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		dirty        string
+		mergedBranch bool // 作業中のブランチがマージ済み（クリーンアップで削除される）かどうか
+		wantErr      bool
+		wantBranch   string
+		wantStash    bool
+		wantRestored bool
+	}{
+		{
+			name:       "デフォルトでは何もせずに終了",
+			wantErr:    true,
+			wantBranch: "feature",
+		},
+		{
+			name:         "stash: 元のブランチに戻って再適用",
+			dirty:        "stash",
+			wantBranch:   "feature",
+			wantStash:    true,
+			wantRestored: true,
+		},
+		{
+			name:         "stash: 元のブランチが削除された場合はスタッシュを残す",
+			dirty:        "stash",
+			mergedBranch: true,
+			wantBranch:   "main",
+			wantStash:    true,
+			wantRestored: false,
+		},
+		{
+			name:       "keep: 変更をデフォルトブランチに持ち越す",
+			dirty:      "keep",
+			wantBranch: "main",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			dir, cleanup := createTestGitRepo(t)
+			defer cleanup()
+			repo := NewRepository(dir)
+
+			runGit(t, dir, "checkout", "-b", "feature")
+			if !tt.mergedBranch {
+				runGit(t, dir, "commit", "--allow-empty", "-m", "feature work")
+			}
+			writeTestFile(t, dir, "test.txt", "work in progress")
+
+			result, err := repo.ExecuteCleanup(t.Context(), CleanupOptions{NoPull: true, Dirty: tt.dirty})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ExecuteCleanup() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr && !IsDirtyWorktree(err) {
+				t.Errorf("ExecuteCleanup() error = %v, want ErrDirtyWorktree", err)
+			}
+
+			if got := runGit(t, dir, "rev-parse", "--abbrev-ref", "HEAD"); got != tt.wantBranch {
+				t.Errorf("current branch = %s, want %s", got, tt.wantBranch)
+			}
+
+			stashes := runGit(t, dir, "stash", "list")
+			if result != nil {
+				if (result.StashRef != "") != tt.wantStash {
+					t.Errorf("StashRef = %q, wantStash %v", result.StashRef, tt.wantStash)
+				}
+				if result.StashRestored != tt.wantRestored {
+					t.Errorf("StashRestored = %v, want %v", result.StashRestored, tt.wantRestored)
+				}
+			}
+
+			// 再適用できなかった場合のみ名前付きのスタッシュが残ること
+			if kept := strings.Contains(stashes, "gitc autostash"); kept != (tt.wantStash && !tt.wantRestored) {
+				t.Errorf("stash list = %q", stashes)
+			}
+
+			// 変更が失われていないこと（スタッシュに残っている場合を除く）
+			if !tt.wantStash || tt.wantRestored {
+				data, err := os.ReadFile(filepath.Join(dir, "test.txt"))
+				if err != nil {
+					t.Fatalf("Failed to read file: %v", err)
+				}
+				if string(data) != "work in progress" {
+					t.Errorf("test.txt = %q, want uncommitted changes to be kept", data)
+				}
+			}
+		})
+	}
+}
+
+// writeTestFile はテスト用のファイルを書き込むヘルパー関数
+func writeTestFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write %s: %v", name, err)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -50,22 +51,29 @@ func createTestGitRepo(t *testing.T) (string, func()) {
 	return dir, cleanup
 }
 
-// 現在のディレクトリを変更して、テスト後に元に戻すヘルパー関数
-func changeDir(t *testing.T, dir string) func() {
+// 指定ディレクトリでGitコマンドを実行するヘルパー関数
+func runGit(t *testing.T, dir string, args ...string) string {
 	t.Helper()
-	
-	oldDir, err := os.Getwd()
+
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
 	if err != nil {
-		t.Fatalf("Failed to get current directory: %v", err)
-	}
-	
-	if err := os.Chdir(dir); err != nil {
-		t.Fatalf("Failed to change directory: %v", err)
-	}
-	
-	return func() {
-		if err := os.Chdir(oldDir); err != nil {
-			t.Errorf("Failed to restore directory: %v", err)
-		}
+		t.Fatalf("git %v failed: %v\n%s", args, err, out)
 	}
-}
\ No newline at end of file
+
+	return strings.TrimSpace(string(out))
+}
+
+// テスト用のベアリポジトリをoriginとして追加し、mainをプッシュするヘルパー関数
+func addTestRemote(t *testing.T, dir string) string {
+	t.Helper()
+
+	remote := filepath.Join(t.TempDir(), "remote.git")
+	runGit(t, dir, "init", "--bare", "-b", "main", remote)
+	runGit(t, dir, "remote", "add", "origin", remote)
+	runGit(t, dir, "push", "-u", "origin", "main")
+	runGit(t, dir, "remote", "set-head", "origin", "main")
+
+	return remote
+}
@@ -0,0 +1,107 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// Worktree は git worktree list で取得したワークツリーの情報を表します
+type Worktree struct {
+	Path     string // ワークツリーのディレクトリ
+	HEAD     string // チェックアウトしているコミット
+	Branch   string // チェックアウトしているブランチ（detached HEADやベアの場合は空）
+	Bare     bool   // ベアリポジトリのエントリかどうか
+	Detached bool   // detached HEADかどうか
+	Locked   bool   // git worktree lock でロックされているかどうか
+	Prunable bool   // ディレクトリが存在せず git worktree prune の対象かどうか
+}
+
+// ListWorktrees はリポジトリのすべてのワークツリーを返します（メインのワークツリーを含む）
+func (r *Repository) ListWorktrees(ctx context.Context) ([]Worktree, error) {
+	result, err := r.ExecuteCommand(ctx, "worktree", "list", "--porcelain")
+	if err != nil {
+		return nil, NewGitError("list-worktrees", err)
+	}
+	return parseWorktreeList(result.Output), nil
+}
+
+// parseWorktreeList は git worktree list --porcelain の出力を解析します
+// 各ワークツリーは "worktree <path>" 行で始まり、空行で区切られます
+func parseWorktreeList(output string) []Worktree {
+	var worktrees []Worktree
+	var current *Worktree
+
+	for _, line := range strings.Split(output, "\n") {
+		key, value, _ := strings.Cut(strings.TrimRight(line, "\r"), " ")
+		switch key {
+		case "worktree":
+			worktrees = append(worktrees, Worktree{Path: filepath.Clean(value)})
+			current = &worktrees[len(worktrees)-1]
+		case "":
+			current = nil
+		}
+		if current == nil {
+			continue
+		}
+
+		switch key {
+		case "HEAD":
+			current.HEAD = value
+		case "branch":
+			current.Branch = strings.TrimPrefix(value, "refs/heads/")
+		case "bare":
+			current.Bare = true
+		case "detached":
+			current.Detached = true
+		case "locked":
+			current.Locked = true
+		case "prunable":
+			current.Prunable = true
+		}
+	}
+
+	return worktrees
+}
+
+// IsWorktreeClean はワークツリーに未コミットの変更や追跡されていないファイルがないか確認します
+func (r *Repository) IsWorktreeClean(ctx context.Context, path string) (bool, error) {
+	result, err := r.worktree(path).ExecuteCommand(ctx, "status", "--porcelain")
+	if err != nil {
+		return false, NewGitError("worktree-status", err).WithPath(path)
+	}
+	return result.Output == "", nil
+}
+
+// RemoveWorktree はワークツリーを削除します
+// 未コミットの変更がある場合、gitが削除を拒否するため強制はしません
+func (r *Repository) RemoveWorktree(ctx context.Context, path string) error {
+	if _, err := r.ExecuteCommand(ctx, "worktree", "remove", path); err != nil {
+		return NewGitError("remove-worktree", err).WithPath(path)
+	}
+	return nil
+}
+
+// worktree は指定したワークツリーでコマンドを実行するRepositoryを返します
+func (r *Repository) worktree(path string) *Repository {
+	return &Repository{Dir: path, Env: r.Env, Runner: r.Runner}
+}
+
+// worktreeBranches はブランチ名からそのブランチをチェックアウトしているワークツリーへの対応を返します
+// currentに指定したワークツリー（gitcを実行しているワークツリー）は含めません
+func worktreeBranches(worktrees []Worktree, current string) map[string]Worktree {
+	branches := make(map[string]Worktree)
+	for _, wt := range worktrees {
+		if wt.Branch == "" || wt.Path == filepath.Clean(current) {
+			continue
+		}
+		branches[wt.Branch] = wt
+	}
+	return branches
+}
+
+// worktreeReason はワークツリーでチェックアウトされているためにスキップする理由を返します
+func worktreeReason(path string) string {
+	return fmt.Sprintf("checked out in worktree %s", path)
+}
@@ -0,0 +1,286 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseWorktreeList(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   []Worktree
+	}{
+		{
+			name:   "出力なし",
+			output: "",
+			want:   nil,
+		},
+		{
+			name: "メインとリンクされたワークツリー",
+			output: "worktree /repo\nHEAD abc\nbranch refs/heads/main\n\n" +
+				"worktree /tmp/my feature\nHEAD def\nbranch refs/heads/feature/x\nlocked reason\n\n" +
+				"worktree /tmp/detached\nHEAD 123\ndetached\nprunable gitdir file points to non-existent location\n",
+			want: []Worktree{
+				{Path: "/repo", HEAD: "abc", Branch: "main"},
+				{Path: "/tmp/my feature", HEAD: "def", Branch: "feature/x", Locked: true},
+				{Path: "/tmp/detached", HEAD: "123", Detached: true, Prunable: true},
+			},
+		},
+		{
+			name:   "ベアリポジトリ",
+			output: "worktree /repo.git\nbare\n\nworktree /wt/main\nHEAD abc\nbranch refs/heads/main\n",
+			want: []Worktree{
+				{Path: "/repo.git", Bare: true},
+				{Path: "/wt/main", HEAD: "abc", Branch: "main"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseWorktreeList(tt.output); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseWorktreeList() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCleanup_Worktrees(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name            string
+		removeWorktrees bool
+		dirty           bool
+		wantDeleted     bool
+		wantReason      string
+	}{
+		{
+			name:        "別のワークツリーでチェックアウトされている場合はスキップ",
+			wantDeleted: false,
+			wantReason:  "checked out in worktree ",
+		},
+		{
+			name:            "--remove-worktrees指定時は変更のないワークツリーを削除してからブランチを削除",
+			removeWorktrees: true,
+			wantDeleted:     true,
+			wantReason:      "removes worktree ",
+		},
+		{
+			name:            "--remove-worktrees指定時でも未コミットの変更があればスキップ",
+			removeWorktrees: true,
+			dirty:           true,
+			wantDeleted:     false,
+			wantReason:      "checked out in worktree ",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			dir, cleanup := createTestGitRepo(t)
+			defer cleanup()
+			repo := NewRepository(dir)
+
+			worktree := filepath.Join(t.TempDir(), "wt")
+			runGit(t, dir, "worktree", "add", "-b", "feature", worktree)
+			if tt.dirty {
+				if err := os.WriteFile(filepath.Join(worktree, "wip.txt"), []byte("wip"), 0644); err != nil {
+					t.Fatalf("Failed to create file: %v", err)
+				}
+			}
+
+			result, err := repo.ExecuteCleanup(t.Context(), CleanupOptions{NoPull: true, RemoveWorktrees: tt.removeWorktrees})
+			if err != nil {
+				t.Fatalf("ExecuteCleanup() error = %v", err)
+			}
+			if len(result.Errors) != 0 {
+				t.Errorf("Errors = %v, want none", result.Errors)
+			}
+
+			var decision *BranchDecision
+			for i := range result.Decisions {
+				if result.Decisions[i].Branch == "feature" {
+					decision = &result.Decisions[i]
+				}
+			}
+			if decision == nil {
+				t.Fatalf("Decisions = %+v, want feature", result.Decisions)
+			}
+			if !contains(decision.Reason, tt.wantReason) {
+				t.Errorf("Reason = %q, want to contain %q", decision.Reason, tt.wantReason)
+			}
+
+			exists, err := repo.BranchExists(t.Context(), "feature")
+			if err != nil {
+				t.Fatalf("BranchExists() error = %v", err)
+			}
+			if exists == tt.wantDeleted {
+				t.Errorf("feature exists = %v, want deleted %v", exists, tt.wantDeleted)
+			}
+
+			_, statErr := os.Stat(worktree)
+			if removed := os.IsNotExist(statErr); removed != tt.wantDeleted {
+				t.Errorf("worktree removed = %v, want %v", removed, tt.wantDeleted)
+			}
+		})
+	}
+}
+
+func TestCleanup_BareLayout(t *testing.T) {
+	t.Parallel()
+
+	// 上流リポジトリ: featureはマージ済み、unmergedは未マージ
+	src, cleanup := createTestGitRepo(t)
+	defer cleanup()
+	runGit(t, src, "branch", "feature")
+	runGit(t, src, "checkout", "-b", "unmerged")
+	runGit(t, src, "commit", "--allow-empty", "-m", "unmerged work")
+	runGit(t, src, "checkout", "main")
+
+	// repo.git/ と feature/, unmerged/ ワークツリーの構成
+	root := t.TempDir()
+	bare := filepath.Join(root, "repo.git")
+	runGit(t, root, "clone", "--bare", "-q", src, bare)
+	runGit(t, bare, "worktree", "add", filepath.Join(root, "feature"), "feature")
+	runGit(t, bare, "worktree", "add", filepath.Join(root, "unmerged"), "unmerged")
+
+	// チェックアウトせずにmainが更新されることを確認するため上流を進める
+	runGit(t, src, "commit", "--allow-empty", "-m", "upstream work")
+	upstream := runGit(t, src, "rev-parse", "main")
+
+	repo, err := OpenRepository(t.Context(), bare)
+	if err != nil {
+		t.Fatalf("OpenRepository() error = %v", err)
+	}
+
+	plan, err := repo.PlanCleanup(t.Context(), CleanupOptions{})
+	if err != nil {
+		t.Fatalf("PlanCleanup() error = %v", err)
+	}
+	if !plan.BareLayout {
+		t.Error("BareLayout = false, want true")
+	}
+	if plan.Checkout != nil {
+		t.Errorf("Checkout = %+v, want nil", plan.Checkout)
+	}
+
+	result, err := repo.ApplyCleanupPlan(t.Context(), plan, CleanupOptions{})
+	if err != nil {
+		t.Fatalf("ApplyCleanupPlan() error = %v", err)
+	}
+	if len(result.Errors) != 0 {
+		t.Errorf("Errors = %v, want none", result.Errors)
+	}
+	if len(result.DeletedBranches) != 1 || result.DeletedBranches[0] != "feature" {
+		t.Errorf("DeletedBranches = %v, want [feature]", result.DeletedBranches)
+	}
+	if _, err := os.Stat(filepath.Join(root, "feature")); !os.IsNotExist(err) {
+		t.Errorf("feature worktree should be removed, stat error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "unmerged")); err != nil {
+		t.Errorf("unmerged worktree should be kept, stat error = %v", err)
+	}
+
+	// チェックアウトせずにデフォルトブランチが更新されていること
+	if got := runGit(t, bare, "rev-parse", "main"); got != upstream {
+		t.Errorf("main = %s, want %s", got, upstream)
+	}
+}
+
+func TestCleanup_BareLayoutFromWorktree(t *testing.T) {
+	t.Parallel()
+
+	// 上流リポジトリ: doneはmainにマージ済みだが、wipには含まれない
+	src, cleanup := createTestGitRepo(t)
+	defer cleanup()
+	runGit(t, src, "checkout", "-b", "wip")
+	runGit(t, src, "commit", "--allow-empty", "-m", "wip work")
+	runGit(t, src, "checkout", "main")
+	runGit(t, src, "commit", "--allow-empty", "-m", "done work")
+	runGit(t, src, "branch", "done")
+
+	root := t.TempDir()
+	bare := filepath.Join(root, "repo.git")
+	runGit(t, root, "clone", "--bare", "-q", src, bare)
+	runGit(t, bare, "worktree", "add", filepath.Join(root, "wip"), "wip")
+
+	// HEADがwipのワークツリーから実行しても、mainにマージ済みのブランチを削除できること
+	repo, err := OpenRepository(t.Context(), filepath.Join(root, "wip"))
+	if err != nil {
+		t.Fatalf("OpenRepository() error = %v", err)
+	}
+	result, err := repo.ExecuteCleanup(t.Context(), CleanupOptions{NoPull: true})
+	if err != nil {
+		t.Fatalf("ExecuteCleanup() error = %v", err)
+	}
+	if len(result.Errors) != 0 {
+		t.Errorf("Errors = %v, want none", result.Errors)
+	}
+	if len(result.DeletedBranches) != 1 || result.DeletedBranches[0] != "done" {
+		t.Errorf("DeletedBranches = %v, want [done]", result.DeletedBranches)
+	}
+	if _, err := os.Stat(filepath.Join(root, "wip")); err != nil {
+		t.Errorf("wip worktree should be kept, stat error = %v", err)
+	}
+}
+
+func TestCleanup_DefaultBranchInOtherWorktree(t *testing.T) {
+	t.Parallel()
+
+	// メインのワークツリーでmainをチェックアウトしたまま、リンクされたワークツリー（wt）から実行する
+	// wtとdoneはどちらもmainにマージ済み
+	dir, cleanup := createTestGitRepo(t)
+	defer cleanup()
+	worktree := filepath.Join(t.TempDir(), "wt")
+	runGit(t, dir, "worktree", "add", "-b", "wt", worktree)
+	runGit(t, dir, "commit", "--allow-empty", "-m", "done work")
+	runGit(t, dir, "branch", "done")
+
+	repo, err := OpenRepository(t.Context(), worktree)
+	if err != nil {
+		t.Fatalf("OpenRepository() error = %v", err)
+	}
+
+	plan, err := repo.PlanCleanup(t.Context(), CleanupOptions{NoPull: true})
+	if err != nil {
+		t.Fatalf("PlanCleanup() error = %v", err)
+	}
+	if plan.Checkout != nil {
+		t.Errorf("Checkout = %+v, want nil", plan.Checkout)
+	}
+	if want := resolvePath(t, dir); plan.DefaultWorktree != want {
+		t.Errorf("DefaultWorktree = %q, want %q", plan.DefaultWorktree, want)
+	}
+
+	// ドライランと適用で結果が一致すること
+	dryRun, err := repo.ApplyCleanupPlan(t.Context(), plan, CleanupOptions{DryRun: true})
+	if err != nil {
+		t.Fatalf("ApplyCleanupPlan(dry-run) error = %v", err)
+	}
+	result, err := repo.ApplyCleanupPlan(t.Context(), plan, CleanupOptions{})
+	if err != nil {
+		t.Fatalf("ApplyCleanupPlan() error = %v", err)
+	}
+	if len(result.Errors) != 0 {
+		t.Errorf("Errors = %v, want none", result.Errors)
+	}
+	for _, got := range [][]string{dryRun.DeletedBranches, result.DeletedBranches} {
+		if len(got) != 1 || got[0] != "done" {
+			t.Errorf("DeletedBranches = %v, want [done]", got)
+		}
+	}
+
+	for _, decision := range result.Decisions {
+		if decision.Branch == "wt" && !contains(decision.Reason, "checked out in worktree "+resolvePath(t, worktree)) {
+			t.Errorf("wt Reason = %q, want checked out in worktree", decision.Reason)
+		}
+	}
+	if got := runGit(t, worktree, "branch", "--show-current"); got != "wt" {
+		t.Errorf("current branch = %s, want wt", got)
+	}
+}